@@ -68,12 +68,17 @@ func toIntSliceOrNil(v starlark.Value) []int {
 	}
 }
 
-// convert starlark value to a list of any values, or nil if not a list
+// convert starlark value to a list of any values, or nil if not a list.
+// None elements become nil
 func toInterfaceSliceOrNil(v starlark.Value) []interface{} {
 	switch x := v.(type) {
 	case *starlark.List:
 		result := make([]interface{}, 0, x.Len())
 		for i := 0; i < x.Len(); i++ {
+			if x.Index(i) == starlark.None {
+				result = append(result, nil)
+				continue
+			}
 			elem, ok := toScalarMaybe(x.Index(i))
 			if !ok {
 				return nil
@@ -84,6 +89,10 @@ func toInterfaceSliceOrNil(v starlark.Value) []interface{} {
 	case starlark.Tuple:
 		result := make([]interface{}, 0, x.Len())
 		for i := 0; i < x.Len(); i++ {
+			if x.Index(i) == starlark.None {
+				result = append(result, nil)
+				continue
+			}
 			elem, ok := toScalarMaybe(x.Index(i))
 			if !ok {
 				return nil
@@ -32,6 +32,7 @@ var Module = &starlarkstruct.Module{
 		"Index":     starlark.NewBuiltin("Index", newIndex),
 		"Series":    starlark.NewBuiltin("Series", newSeries),
 		"abs":       starlark.NewBuiltin("mathAbs", mathAbs),
+		"slice":     starlark.NewBuiltin("slice", newSlice),
 	},
 }
 
@@ -856,6 +857,18 @@ func dataframeAttrAt(self *DataFrame) (starlark.Value, error) {
 	return NewAtIndexer(self), nil
 }
 
+// loc returns a LocIndexer which can retrieve or set rows, columns and cells
+// by label
+func dataframeAttrLoc(self *DataFrame) (starlark.Value, error) {
+	return NewLocIndexer(self), nil
+}
+
+// iloc returns an ILocIndexer which can retrieve or set rows, columns and
+// cells by position
+func dataframeAttrILoc(self *DataFrame) (starlark.Value, error) {
+	return NewILocIndexer(self), nil
+}
+
 // columns returns the columns of the dataframe as an index
 func dataframeAttrColumns(self *DataFrame) (starlark.Value, error) {
 	if self.columns == nil {
@@ -984,11 +997,15 @@ func dataframeHead(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple,
 
 // groupby method returns a grouped set of rows collected by some given column value
 func dataframeGroupBy(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
-	var by starlark.Value
-	self := b.Receiver().(*DataFrame)
+	var (
+		by      starlark.Value
+		asIndex = true
+		self    = b.Receiver().(*DataFrame)
+	)
 
 	if err := starlark.UnpackArgs("groupby", args, kwargs,
 		"by", &by,
+		"as_index?", &asIndex,
 	); err != nil {
 		return nil, err
 	}
@@ -1017,7 +1034,14 @@ func dataframeGroupBy(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tup
 		result[groupValue] = append(result[groupValue], r)
 	}
 
-	return &GroupByResult{label: groupBy, columns: self.columns, dfIndex: self.index, grouping: result}, nil
+	return &GroupByResult{
+		label:    groupBy,
+		columns:  self.columns,
+		dfIndex:  self.index,
+		grouping: result,
+		asIndex:  asIndex,
+		outconf:  self.outconf,
+	}, nil
 }
 
 // drop method returns a copy of a DataFrame with rows or columns dropped
@@ -1152,118 +1176,6 @@ func dataframeDropDuplicates(_ *starlark.Thread, b *starlark.Builtin, args starl
 	return newDataFrameConstructor(body, self.columns, nil, self.outconf)
 }
 
-// merge method merges the rows of two DataFrames
-func dataframeMerge(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
-	var (
-		right, leftOn, rightOn, how starlark.Value
-		suffixesVal                 starlark.Value
-		self                        = b.Receiver().(*DataFrame)
-	)
-
-	if err := starlark.UnpackArgs("merge", args, kwargs,
-		"right", &right,
-		"left_on?", &leftOn,
-		"right_on?", &rightOn,
-		"how?", &how,
-		"suffixes?", &suffixesVal,
-	); err != nil {
-		return nil, err
-	}
-
-	rightFrame, ok := right.(*DataFrame)
-	if !ok {
-		return starlark.None, fmt.Errorf("`right` must be a DataFrame")
-	}
-
-	leftOnStr := toStr(leftOn)
-	rightOnStr := toStr(rightOn)
-	leftKey := 0
-	rightKey := 0
-	if leftOnStr != "" {
-		leftKey = findKeyPos(leftOnStr, self.columns.Columns())
-		rightKey = findKeyPos(rightOnStr, rightFrame.columns.Columns())
-		if leftKey == -1 {
-			return starlark.None, fmt.Errorf("left key %q not found", leftOnStr)
-		}
-		if rightKey == -1 {
-			return starlark.None, fmt.Errorf("right key %q not found", rightOnStr)
-		}
-	}
-
-	howStr := toStrOrEmpty(how)
-
-	var leftOrder []int
-	if howStr == "" || howStr == "inner" {
-		// For an inner merge, the keys appear with identical keys appearing together
-		seen := make(map[string]int)
-		// Indices are collected using a list of list of ints. Each of the dataframe
-		// rows with the same key will have their indices appear adjacent to each other.
-		// For example, when running the test dataframe_merge.star, for the first
-		// call to `df1.merge`, the `idxs` array will be [[0, 3], [1], [2]], caused by
-		// the key "foo" appearing at positions 0 and 3: its rows end up together.
-		idxs := make([][]int, 0)
-		for rowIter := newRowIter(self); !rowIter.Done(); rowIter.Next() {
-			key := rowIter.GetStr(leftKey)
-			n, has := seen[key]
-			if has {
-				idxs[n] = append(idxs[n], rowIter.Index())
-			} else {
-				n = len(idxs)
-				idxs = append(idxs, []int{rowIter.Index()})
-				seen[key] = n
-			}
-		}
-		// Collect the rows now based upon the desired order
-		for _, numList := range idxs {
-			leftOrder = append(leftOrder, numList...)
-		}
-	} else if howStr == "left" {
-		leftOrder = nil
-	} else {
-		return starlark.None, fmt.Errorf("not implemented: `how` is %q", howStr)
-	}
-
-	var suffixes []string
-	leftList := toStrSliceOrNil(suffixesVal)
-
-	// TODO(dustmop): Ensure suffixes are the right length, add a test
-	if len(leftList) == 2 {
-		suffixes = leftList
-	} else {
-		// Default column indicies are "_x" and "_y"
-		suffixes = []string{"_x", "_y"}
-	}
-
-	// If column names of the merge key are the same, don't include the second one, ignore it
-	ignore := findKeyPos(self.columns.Columns()[leftKey], rightFrame.columns.Columns())
-
-	leftColumns := addSuffixToStringList(self.columns.Columns(), suffixes[0], leftKey)
-	rightColumns := addSuffixToStringList(rightFrame.columns.Columns(), suffixes[1], rightKey)
-	if ignore != -1 {
-		rightColumns = removeElemFromStringList(rightColumns, ignore)
-	}
-	newColumns := append(leftColumns, rightColumns...)
-
-	builder := newTableBuilder(len(newColumns), 0)
-	leftIter := newRowIterWithOrder(self, leftOrder)
-	for ; !leftIter.Done(); leftIter.Next() {
-		for rightIter := newRowIter(rightFrame); !rightIter.Done(); rightIter.Next() {
-			newRow := leftIter.MergeWith(rightIter, leftKey, rightKey, ignore)
-			if newRow != nil {
-				builder.pushRow(newRow.data)
-			}
-		}
-	}
-
-	// Finish building the body, return any errors
-	body, err := builder.body()
-	if err != nil {
-		return nil, err
-	}
-	// TODO(dustmop): Work with other index types, test this case
-	return newDataFrameConstructor(body, NewTextIndex(newColumns, ""), nil, self.outconf)
-}
-
 func dataframeSortValues(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 	var (
 		byList, ascendingVal starlark.Value
@@ -1488,18 +1400,6 @@ func dataframeShift(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple
 	return newDataFrameConstructor(body, self.columns, self.index, self.outconf)
 }
 
-func addSuffixToStringList(names []string, suffix string, keyPos int) []string {
-	result := make([]string, len(names))
-	for i, elem := range names {
-		if i == keyPos {
-			result[i] = elem
-		} else {
-			result[i] = fmt.Sprintf("%s%s", elem, suffix)
-		}
-	}
-	return result
-}
-
 func removeElemFromStringList(ls []string, i int) []string {
 	if i == -1 {
 		return ls
@@ -16,10 +16,10 @@ var dataframeAttributes = map[string]dataframeAttrImpl{
 	"dtypes":  attrNoImplDataframe("dtypes"),
 	"empty":   attrNoImplDataframe("empty"),
 	"flags":   attrNoImplDataframe("flags"),
-	"iat":     attrNoImplDataframe("iat"),
-	"iloc":    attrNoImplDataframe("iloc"),
+	"iat":     dataframeAttrAt,
+	"iloc":    dataframeAttrILoc,
 	"index":   dataframeAttrIndex,
-	"loc":     attrNoImplDataframe("loc"),
+	"loc":     dataframeAttrLoc,
 	"ndim":    attrNoImplDataframe("ndim"),
 	"shape":   dataframeAttrShape,
 	"size":    attrNoImplDataframe("sizes"),
@@ -45,9 +45,9 @@ var dataframeMethods = map[string]*starlark.Builtin{
 	"assign":            starlark.NewBuiltin("assign", dataframeAssign),
 	"astype":            starlark.NewBuiltin("astype", methNoImpl("astype")),
 	"at_time":           starlark.NewBuiltin("at_time", methNoImpl("at_time")),
-	"backfill":          starlark.NewBuiltin("backfill", methNoImpl("backfill")),
+	"backfill":          starlark.NewBuiltin("backfill", dataframeFillMethod),
 	"between_time":      starlark.NewBuiltin("between_time", methNoImpl("between_time")),
-	"bfill":             starlark.NewBuiltin("bfill", methNoImpl("bfill")),
+	"bfill":             starlark.NewBuiltin("bfill", dataframeFillMethod),
 	"bool":              starlark.NewBuiltin("bool", methNoImpl("bool")),
 	"boxplot":           starlark.NewBuiltin("boxplot", methMissing("boxplot")),
 	"clip":              starlark.NewBuiltin("clip", methNoImpl("clip")),
@@ -58,13 +58,13 @@ var dataframeMethods = map[string]*starlark.Builtin{
 	"copy":              starlark.NewBuiltin("copy", methNoImpl("copy")),
 	"corr":              starlark.NewBuiltin("corr", methNoImpl("corr")),
 	"corrwith":          starlark.NewBuiltin("corrwith", methNoImpl("corrwith")),
-	"count":             starlark.NewBuiltin("count", methNoImpl("count")),
+	"count":             starlark.NewBuiltin("count", dataframeStat),
 	"cov":               starlark.NewBuiltin("cov", methNoImpl("cov")),
 	"cummax":            starlark.NewBuiltin("cummax", methNoImpl("cummax")),
 	"cummin":            starlark.NewBuiltin("cummin", methNoImpl("cummin")),
 	"cumprod":           starlark.NewBuiltin("cumprod", methNoImpl("cumprod")),
 	"cumsum":            starlark.NewBuiltin("cumsum", methNoImpl("cumsum")),
-	"describe":          starlark.NewBuiltin("describe", methNoImpl("describe")),
+	"describe":          starlark.NewBuiltin("describe", dataframeDescribe),
 	"diff":              starlark.NewBuiltin("diff", methNoImpl("diff")),
 	"div":               starlark.NewBuiltin("div", methNoImpl("div")),
 	"divide":            starlark.NewBuiltin("divide", methNoImpl("divide")),
@@ -72,7 +72,7 @@ var dataframeMethods = map[string]*starlark.Builtin{
 	"drop":              starlark.NewBuiltin("drop", dataframeDrop),
 	"drop_duplicates":   starlark.NewBuiltin("drop_duplicates", dataframeDropDuplicates),
 	"droplevel":         starlark.NewBuiltin("droplevel", methNoImpl("droplevel")),
-	"dropna":            starlark.NewBuiltin("dropna", methNoImpl("dropna")),
+	"dropna":            starlark.NewBuiltin("dropna", dataframeDropNa),
 	"duplicated":        starlark.NewBuiltin("duplicated", methNoImpl("duplicated")),
 	"eq":                starlark.NewBuiltin("eq", methNoImpl("eq")),
 	"equals":            starlark.NewBuiltin("equals", methNoImpl("equals")),
@@ -80,8 +80,8 @@ var dataframeMethods = map[string]*starlark.Builtin{
 	"ewm":               starlark.NewBuiltin("ewm", methNoImpl("ewm")),
 	"expanding":         starlark.NewBuiltin("expanding", methNoImpl("expanding")),
 	"explode":           starlark.NewBuiltin("explode", methNoImpl("explode")),
-	"ffill":             starlark.NewBuiltin("ffill", methNoImpl("ffill")),
-	"fillna":            starlark.NewBuiltin("fillna", methNoImpl("fillna")),
+	"ffill":             starlark.NewBuiltin("ffill", dataframeFillMethod),
+	"fillna":            starlark.NewBuiltin("fillna", dataframeFillNa),
 	"filter":            starlark.NewBuiltin("filter", methNoImpl("filter")),
 	"first":             starlark.NewBuiltin("first", methNoImpl("first")),
 	"first_valid_index": starlark.NewBuiltin("first_valid_index", methNoImpl("first_valid_index")),
@@ -99,10 +99,10 @@ var dataframeMethods = map[string]*starlark.Builtin{
 	"infer_objects":     starlark.NewBuiltin("infer_objects", methNoImpl("infer_objects")),
 	"info":              starlark.NewBuiltin("info", methNoImpl("info")),
 	"insert":            starlark.NewBuiltin("insert", methNoImpl("insert")),
-	"interpolate":       starlark.NewBuiltin("interpolate", methNoImpl("interpolate")),
+	"interpolate":       starlark.NewBuiltin("interpolate", dataframeInterpolate),
 	"isin":              starlark.NewBuiltin("isin", methNoImpl("isin")),
-	"isna":              starlark.NewBuiltin("isna", methNoImpl("isna")),
-	"isnull":            starlark.NewBuiltin("isnull", methNoImpl("isnull")),
+	"isna":              starlark.NewBuiltin("isna", dataframeIsNa),
+	"isnull":            starlark.NewBuiltin("isnull", dataframeIsNa),
 	"items":             starlark.NewBuiltin("items", methNoImpl("items")),
 	"iteritems":         starlark.NewBuiltin("iteritems", methNoImpl("iteritems")),
 	"iterrows":          starlark.NewBuiltin("iterrows", methNoImpl("iterrows")),
@@ -118,24 +118,24 @@ var dataframeMethods = map[string]*starlark.Builtin{
 	"lt":                starlark.NewBuiltin("lt", methNoImpl("lt")),
 	"mad":               starlark.NewBuiltin("mad", methNoImpl("mad")),
 	"mask":              starlark.NewBuiltin("mask", methNoImpl("mask")),
-	"max":               starlark.NewBuiltin("max", methNoImpl("max")),
-	"mean":              starlark.NewBuiltin("mean", methNoImpl("mean")),
-	"median":            starlark.NewBuiltin("median", methNoImpl("median")),
+	"max":               starlark.NewBuiltin("max", dataframeStat),
+	"mean":              starlark.NewBuiltin("mean", dataframeStat),
+	"median":            starlark.NewBuiltin("median", dataframeStat),
 	"melt":              starlark.NewBuiltin("melt", methNoImpl("melt")),
 	"memory_usage":      starlark.NewBuiltin("memory_usage", methNoImpl("memory_usage")),
 	"merge":             starlark.NewBuiltin("merge", dataframeMerge),
-	"min":               starlark.NewBuiltin("min", methNoImpl("min")),
+	"min":               starlark.NewBuiltin("min", dataframeStat),
 	"mod":               starlark.NewBuiltin("mod", methNoImpl("mod")),
 	"mode":              starlark.NewBuiltin("mode", methNoImpl("mode")),
 	"mul":               starlark.NewBuiltin("mul", methNoImpl("mul")),
 	"multiply":          starlark.NewBuiltin("multiply", methNoImpl("multiply")),
 	"ne":                starlark.NewBuiltin("ne", methNoImpl("ne")),
 	"nlargest":          starlark.NewBuiltin("nlargest", methNoImpl("nlargest")),
-	"notna":             starlark.NewBuiltin("notna", methNoImpl("notna")),
-	"notnull":           starlark.NewBuiltin("notnull", methNoImpl("notnull")),
+	"notna":             starlark.NewBuiltin("notna", dataframeNotNa),
+	"notnull":           starlark.NewBuiltin("notnull", dataframeNotNa),
 	"nsmallest":         starlark.NewBuiltin("nsmallest", methNoImpl("nsmallest")),
 	"nunique":           starlark.NewBuiltin("nunique", methNoImpl("nunique")),
-	"pad":               starlark.NewBuiltin("pad", methNoImpl("pad")),
+	"pad":               starlark.NewBuiltin("pad", dataframeFillMethod),
 	"pct_change":        starlark.NewBuiltin("pct_change", methNoImpl("pct_change")),
 	"pipe":              starlark.NewBuiltin("pipe", methNoImpl("pipe")),
 	"pivot":             starlark.NewBuiltin("pivot", methNoImpl("pivot")),
@@ -145,7 +145,7 @@ var dataframeMethods = map[string]*starlark.Builtin{
 	"pow":               starlark.NewBuiltin("pow", methNoImpl("pow")),
 	"prod":              starlark.NewBuiltin("prod", methNoImpl("prod")),
 	"product":           starlark.NewBuiltin("product", methNoImpl("product")),
-	"quantile":          starlark.NewBuiltin("quantile", methNoImpl("quantile")),
+	"quantile":          starlark.NewBuiltin("quantile", dataframeQuantile),
 	"query":             starlark.NewBuiltin("query", methNoImpl("query")),
 	"radd":              starlark.NewBuiltin("radd", methNoImpl("radd")),
 	"rank":              starlark.NewBuiltin("rank", methNoImpl("rank")),
@@ -180,10 +180,10 @@ var dataframeMethods = map[string]*starlark.Builtin{
 	"sparse":            starlark.NewBuiltin("sparse", methNoImpl("sparse")),
 	"squeeze":           starlark.NewBuiltin("squeeze", methNoImpl("squeeze")),
 	"stack":             starlark.NewBuiltin("stack", methNoImpl("stack")),
-	"std":               starlark.NewBuiltin("std", methNoImpl("std")),
+	"std":               starlark.NewBuiltin("std", dataframeStat),
 	"sub":               starlark.NewBuiltin("sub", methNoImpl("sub")),
 	"subtract":          starlark.NewBuiltin("subtract", methNoImpl("subtract")),
-	"sum":               starlark.NewBuiltin("sum", methNoImpl("sum")),
+	"sum":               starlark.NewBuiltin("sum", dataframeStat),
 	"swapaxes":          starlark.NewBuiltin("swapaxes", methNoImpl("swapaxes")),
 	"swaplevel":         starlark.NewBuiltin("swaplevel", methNoImpl("swaplevel")),
 	"tail":              starlark.NewBuiltin("tail", methNoImpl("tail")),
@@ -220,7 +220,7 @@ var dataframeMethods = map[string]*starlark.Builtin{
 	"unstack":           starlark.NewBuiltin("unstack", methNoImpl("unstack")),
 	"update":            starlark.NewBuiltin("update", methNoImpl("update")),
 	"value_counts":      starlark.NewBuiltin("value_counts", methNoImpl("value_counts")),
-	"var":               starlark.NewBuiltin("var", methNoImpl("var")),
+	"var":               starlark.NewBuiltin("var", dataframeStat),
 	"where":             starlark.NewBuiltin("where", methNoImpl("where")),
 	"xs":                starlark.NewBuiltin("xs", methNoImpl("xs")),
 }
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"go.starlark.net/starlark"
 )
 
 func TestDataframeBasic(t *testing.T) {
@@ -60,6 +61,54 @@ func TestDataframeAt(t *testing.T) {
 	expectScriptOutput(t, "testdata/dataframe_at.star", "testdata/dataframe_at.expect.txt")
 }
 
+func TestDataframeLoc(t *testing.T) {
+	expectScriptOutput(t, "testdata/dataframe_loc.star", "testdata/dataframe_loc.expect.txt")
+}
+
+func TestDataframeLocMissingLabel(t *testing.T) {
+	_, err := runScript(t, "testdata/dataframe_loc_missing.star")
+	if err == nil {
+		t.Fatal("error expected, did not get one")
+	}
+	expectErr := `loc: label "legs" not found`
+	if err.Error() != expectErr {
+		t.Errorf("error mismatch\nwant: %s\ngot: %s", expectErr, err)
+	}
+}
+
+func TestDataframeILocOutOfBounds(t *testing.T) {
+	_, err := runScript(t, "testdata/dataframe_iloc_bounds.star")
+	if err == nil {
+		t.Fatal("error expected, did not get one")
+	}
+	expectErr := `iloc: position 99 is out of bounds for axis with size 2`
+	if err.Error() != expectErr {
+		t.Errorf("error mismatch\nwant: %s\ngot: %s", expectErr, err)
+	}
+
+	df, err := NewDataFrame([][]interface{}{{"cat", 4}, {"dog", 4}}, []string{"name", "legs"}, nil, &OutputConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	series := newSeriesFromInts([]int{1, 2, 3}, nil, "")
+	cases := []struct {
+		indexer *ILocIndexer
+		key     starlark.Value
+		expect  string
+	}{
+		{NewILocIndexer(df), starlark.MakeInt(-3), "iloc: position -3 is out of bounds for axis with size 2"},
+		{NewILocIndexer(df), starlark.Tuple{starlark.MakeInt(0), starlark.MakeInt(2)}, "iloc: position 2 is out of bounds for axis with size 2"},
+		{NewILocIndexer(df), starlark.NewList([]starlark.Value{starlark.MakeInt(0), starlark.MakeInt(5)}), "iloc: position 5 is out of bounds for axis with size 2"},
+		{&ILocIndexer{locator{series: series, positional: true}}, starlark.MakeInt(3), "iloc: position 3 is out of bounds for axis with size 3"},
+	}
+	for _, c := range cases {
+		_, _, err := c.indexer.Get(c.key)
+		if err == nil || err.Error() != c.expect {
+			t.Errorf("key %s: error mismatch\nwant: %s\ngot: %v", c.key, c.expect, err)
+		}
+	}
+}
+
 func TestDataframeSetKey(t *testing.T) {
 	expectScriptOutput(t, "testdata/dataframe_setkey.star", "testdata/dataframe_setkey.expect.txt")
 }
@@ -102,6 +151,32 @@ func TestDataframeMerge(t *testing.T) {
 	expectScriptOutput(t, "testdata/dataframe_merge.star", "testdata/dataframe_merge.expect.txt")
 }
 
+func TestDataframeMergeHow(t *testing.T) {
+	expectScriptOutput(t, "testdata/dataframe_merge_how.star", "testdata/dataframe_merge_how.expect.txt")
+}
+
+func TestDataframeMergeValidate(t *testing.T) {
+	_, err := runScript(t, "testdata/dataframe_merge_validate.star")
+	if err == nil {
+		t.Fatal("error expected, did not get one")
+	}
+	expectErr := `merge keys are not unique in left dataset; not a one-to-one merge`
+	if err.Error() != expectErr {
+		t.Errorf("error mismatch\nwant: %s\ngot: %s", expectErr, err)
+	}
+}
+
+func TestDataframeMergeSuffixes(t *testing.T) {
+	_, err := runScript(t, "testdata/dataframe_merge_suffixes.star")
+	if err == nil {
+		t.Fatal("error expected, did not get one")
+	}
+	expectErr := "`suffixes` must be a list of 2 strings"
+	if err.Error() != expectErr {
+		t.Errorf("error mismatch\nwant: %s\ngot: %s", expectErr, err)
+	}
+}
+
 func TestDataframeSort(t *testing.T) {
 	expectScriptOutput(t, "testdata/dataframe_sort.star", "testdata/dataframe_sort.expect.txt")
 }
@@ -129,10 +204,33 @@ func TestDataframeGroupBy(t *testing.T) {
 	expectScriptOutput(t, "testdata/dataframe_groupby.star", "testdata/dataframe_groupby.expect.txt")
 }
 
+func TestDataframeGroupByAgg(t *testing.T) {
+	expectScriptOutput(t, "testdata/dataframe_groupby_agg.star", "testdata/dataframe_groupby_agg.expect.txt")
+}
+
+func TestDataframeMissing(t *testing.T) {
+	expectScriptOutput(t, "testdata/dataframe_missing.star", "testdata/dataframe_missing.expect.txt")
+}
+
 func TestDataframeShift(t *testing.T) {
 	expectScriptOutput(t, "testdata/dataframe_shift.star", "testdata/dataframe_shift.expect.txt")
 }
 
+func TestDataframeStats(t *testing.T) {
+	expectScriptOutput(t, "testdata/dataframe_stats.star", "testdata/dataframe_stats.expect.txt")
+}
+
+func TestDataframeStatsNotNumeric(t *testing.T) {
+	_, err := runScript(t, "testdata/dataframe_stats_not_numeric.star")
+	if err == nil {
+		t.Fatal("error expected, did not get one")
+	}
+	expectErr := `mean: column "name" is not numeric, use numeric_only=True to skip it`
+	if err.Error() != expectErr {
+		t.Errorf("error mismatch\nwant: %s\ngot: %s", expectErr, err)
+	}
+}
+
 func TestDataframeStringify(t *testing.T) {
 	expectScriptOutput(t, "testdata/dataframe_stringify.star",
 		"testdata/dataframe_stringify.expect.txt")
@@ -193,7 +291,7 @@ func TestDataframeNotImplemented(t *testing.T) {
 	if err == nil {
 		t.Fatal("error expected, did not get one")
 	}
-	expectErr := `dataframe.ffill is not implemented. If you need this functionality to exist, file an issue at 'https://github.com/qri-io/starlib/issues' with the title 'dataframe.ffill needs implementation'. Please first search if an issue exists already`
+	expectErr := `dataframe.melt is not implemented. If you need this functionality to exist, file an issue at 'https://github.com/qri-io/starlib/issues' with the title 'dataframe.melt needs implementation'. Please first search if an issue exists already`
 	if err.Error() != expectErr {
 		t.Errorf("error mismatch\nwant: %s\ngot: %s", expectErr, err)
 	}
@@ -48,6 +48,15 @@
             data type of the values in the Series
           name string
             name of the Series
+      slice(start?, stop, step?) slice
+        constructs a slice, which selects a range of rows or columns when used as a key of loc or iloc. Called with a single argument, that argument is the stop. Unlike slice expressions such as df.iloc[1:3], a slice can be combined with a column key: df.loc[dataframe.slice("a", "c"), "name"]
+        params:
+          start any
+            the first label or position to select, defaults to the start of the axis
+          stop any
+            the label or position to stop at. loc includes the stop label, iloc excludes the stop position
+          step int
+            select every step-th label or position, negative steps select in reverse
     types:
       DataFrame
         a dataframe
@@ -64,6 +73,18 @@
                 the function to apply to each slice
               axis int
                 which to travel, either 0 for columns, or 1 for rows
+          bfill(limit?) DataFrame
+            fill missing values with the next present value in its column
+            params:
+              limit int
+                the most consecutive missing values to fill
+          count(skipna?, numeric_only?) Series
+            the number of present values of each column
+          describe(percentiles?) DataFrame
+            summarize each column. If there are numeric columns, they get their count, mean, std, min, percentiles and max. Otherwise columns get their count, number of unique values, most frequent value and its frequency
+            params:
+              percentiles list(float)
+                percentiles to include, defaulting to [0.25, 0.5, 0.75]. The median is always included
           drop(labels, axis, index, columns)
             drop columns or rows from the DataFrame
             params:
@@ -80,11 +101,38 @@
             params:
               subset list(string)
                 which subset of each row to consider for uniqueness
-          groupby(by) GroupByResult
+          dropna(axis?, how?, thresh?, subset?) DataFrame
+            drop the rows, or columns, that have missing values
+            params:
+              axis int,string
+                0 or "index" to drop rows, 1 or "columns" to drop columns. default is 0
+              how string
+                "any" drops when any value is missing, "all" drops when every value is missing. default is "any"
+              thresh int
+                keep only those with at least this many present values. cannot be used with how
+              subset string,list(string)
+                labels along the other axis to check for missing values, such as columns when dropping rows
+          ffill(limit?) DataFrame
+            fill missing values with the previous present value in its column
+            params:
+              limit int
+                the most consecutive missing values to fill
+          fillna(value?, method?, limit?) DataFrame
+            fill missing values, which are None or NaN, with a value or a method
+            params:
+              value any
+                a value to fill with, or a dict mapping column names to values
+              method string
+                fill using "ffill" or "bfill" instead of a value
+              limit int
+                the most values to fill with a value, or the most consecutive values to fill with a method
+          groupby(by, as_index?) GroupByResult
             group a set of row according to some given column value
             params:
               by list(string)
                 a list of column names to use for grouping the rows together
+              as_index bool
+                whether aggregations use the groups as their index, or add them as the first column. default is True
             examples:
               groupby
                 group rows according to the values in the given column
@@ -102,19 +150,75 @@
             params:
               n int
                 number of rows to include, defaulting to 5
-          merge(right, left_on, right_on, how, suffixes) DataFrame
+          interpolate(method?, limit?) DataFrame
+            fill missing values by linear interpolation between the present values around them down each column. Columns with values other than numbers are kept as they are. Missing values at the end get the last present value
+            params:
+              method string
+                only "linear" is supported
+              limit int
+                the most consecutive missing values to fill
+          isna() DataFrame
+            return a DataFrame of bools for whether each element is missing. isnull is an alias
+          max(skipna?, numeric_only?) Series
+            the largest value of each column
+            params:
+              skipna bool
+                skip missing values, default is True. if False, missing values make the result NaN
+              numeric_only bool
+                only include numeric columns, default is False
+          mean(skipna?, numeric_only?) Series
+            the mean of each column
+            params:
+              skipna bool
+                skip missing values, default is True. if False, missing values make the result NaN
+              numeric_only bool
+                only include numeric columns, default is False
+          median(skipna?, numeric_only?) Series
+            the median of each column
+            params:
+              skipna bool
+                skip missing values, default is True. if False, missing values make the result NaN
+              numeric_only bool
+                only include numeric columns, default is False
+          merge(right, left_on, right_on, how, suffixes, on, left_index, right_index, indicator, validate) DataFrame
             merge this with the right DataFrame, returned as a new DataFrame
             params:
               right DataFrame
                 the DataFrame to merge with this one
-              left_on string
-                which column of the left DataFrame to merge on
-              right_on string
-                which column of the right DataFrame to merge on
+              left_on string,list(string)
+                which columns of the left DataFrame to merge on
+              right_on string,list(string)
+                which columns of the right DataFrame to merge on
               how string
-                how to merge the columns, only "inner" is supported, and is the default
+                how to merge rows, one of "inner", "left", "right", "outer" or "cross", defaulting to "inner"
               suffixes list(string)
                 suffixes to use for merged column names, defaulting to ["_x", "_y"]
+              on string,list(string)
+                columns both DataFrames have to merge on. when no keys are given, DataFrames merge on their first columns
+              left_index bool
+                merge using the index of the left DataFrame
+              right_index bool
+                merge using the index of the right DataFrame
+              indicator bool,string
+                add a column saying whether each row is from "both" DataFrames, or is "left_only" or "right_only". True names the column "_merge"
+              validate string
+                check that keys are unique, one of "one_to_one", "one_to_many", "many_to_one" or "many_to_many"
+          min(skipna?, numeric_only?) Series
+            the smallest value of each column
+            params:
+              skipna bool
+                skip missing values, default is True. if False, missing values make the result NaN
+              numeric_only bool
+                only include numeric columns, default is False
+          notna() DataFrame
+            return a DataFrame of bools for whether each element is present. notnull is an alias
+          quantile(q?, numeric_only?) Series,DataFrame
+            the value at a quantile of each column, interpolating between values. A list of quantiles returns a DataFrame with a row for each
+            params:
+              q float,list(float)
+                the quantile between 0 and 1, defaulting to 0.5
+              numeric_only bool
+                only include numeric columns, default is False
           reset_index()
             resets the index to be an empty index, turning the previous index into its own column
           sort_values(by, ascending?) DataFrame
@@ -135,16 +239,93 @@
                                                  [3,'eel','zap'],
                                                  [4,'frog','ribbit']])
                   sorted = df.sort_values(by=['sound'])
+          std(skipna?, numeric_only?, ddof?) Series
+            the standard deviation of each column
+            params:
+              skipna bool
+                skip missing values, default is True. if False, missing values make the result NaN
+              numeric_only bool
+                only include numeric columns, default is False
+              ddof int
+                delta degrees of freedom, defaulting to 1
+          sum(skipna?, numeric_only?) Series
+            the sum of each column
+            params:
+              skipna bool
+                skip missing values, default is True. if False, missing values make the result NaN
+              numeric_only bool
+                only include numeric columns, default is False
+          var(skipna?, numeric_only?, ddof?) Series
+            the variance of each column
+            params:
+              skipna bool
+                skip missing values, default is True. if False, missing values make the result NaN
+              numeric_only bool
+                only include numeric columns, default is False
+              ddof int
+                delta degrees of freedom, defaulting to 1
         fields:
           at AtIndexer
             returns an AtIndexer, which can be used to retrieve an arbitrary cell from the DataFrame
           columns Index
             returns the columns of the DataFrame as an Index
+          iat AtIndexer
+            returns an AtIndexer, which can be used to retrieve an arbitrary cell from the DataFrame by position
+          iloc ILocIndexer
+            returns an ILocIndexer, which retrieves or assigns rows, columns and cells by position. Keys are a row key, or a tuple of a row key and a column key. Each key can be an int, a list of ints, a slice, or a list or Series of bools. A DataFrame with iloc supports slice expressions, such as df.iloc[1:3]
           index Index
             returns the Index of the DataFrame, if it exists
+          loc LocIndexer
+            returns a LocIndexer, which retrieves or assigns rows, columns and cells by label. Keys are a row key, or a tuple of a row key and a column key. Each key can be a label, a list of labels, a slice, or a list or Series of bools. Selecting a single row and column returns a cell, selecting a single row or column returns a Series, otherwise a DataFrame is returned. Assigned values can be a scalar, a list or Series with a value for each selected cell, or a DataFrame or list of lists when selecting several rows and columns
           shape tuple(int,int)
             returns a tuple with the size of the DataFrame, as (number rows, number columns)
 
+      GroupByResult
+        rows of a DataFrame grouped by a column, returned by DataFrame.groupby. Indexing by a column name returns a SeriesGroupByResult. Aggregations return a DataFrame with a column for each column that supports the aggregation
+        methods:
+          agg(func) DataFrame
+            aggregate columns of each group
+            params:
+              func string,list(string),dict
+                an aggregation to apply to every column, a list of them, or a dict mapping column names to aggregations. Columns given a list of aggregations are named column_aggregation
+            examples:
+              agg
+                sum and average the sales of each store
+                code:
+                  load("dataframe.star", "dataframe")
+                  df = dataframe.DataFrame({"store": ["a", "b", "a"], "sales": [5, 8, 7]})
+                  totals = df.groupby(["store"]).agg({"sales": ["sum", "mean"]})
+          count()
+            the number of non-null values in each group
+          first()
+            the first non-null value in each group
+          last()
+            the last non-null value in each group
+          max()
+            the largest value in each group
+          mean()
+            the mean of each group
+          median()
+            the median of each group
+          min()
+            the smallest value in each group
+          nunique()
+            the number of distinct non-null values in each group
+          size()
+            the number of rows in each group
+          std(ddof?)
+            the standard deviation of each group
+            params:
+              ddof int
+                delta degrees of freedom, defaulting to 1
+          sum()
+            the sum of each group
+          var(ddof?)
+            the variance of each group
+            params:
+              ddof int
+                delta degrees of freedom, defaulting to 1
+
       Index
         an index, which is used to describe an axis of a DataFrame
         fields:
@@ -161,25 +342,169 @@
             params:
               type string
                 a string representing a type
+          bfill(limit?) Series
+            fill missing values with the next present value
+            params:
+              limit int
+                the most consecutive missing values to fill
+          count(skipna?, numeric_only?) int
+            the number of present values
+          describe(percentiles?) Series
+            summarize the Series. Numeric Series get their count, mean, std, min, percentiles and max, others get their count, number of unique values, most frequent value and its frequency
+            params:
+              percentiles list(float)
+                percentiles to include, defaulting to [0.25, 0.5, 0.75]. The median is always included
+          dropna() Series
+            drop missing values
           equals(value) Series
             return a Series of bools for whether each element is equal to the value
             params:
               value any
                 value to compare each element to
+          ffill(limit?) Series
+            fill missing values with the previous present value
+            params:
+              limit int
+                the most consecutive missing values to fill
+          fillna(value?, method?, limit?) Series
+            fill missing values, which are None or NaN, with a value or a method
+            params:
+              value any
+                a value to fill with
+              method string
+                fill using "ffill" or "bfill" instead of a value
+              limit int
+                the most values to fill with a value, or the most consecutive values to fill with a method
           get(index) any
             gets the cell at the given index
             params:
               index any
                 either an int or a name from the index
+          interpolate(method?, limit?) Series
+            fill missing values by linear interpolation between the present values around them. Missing values at the end get the last present value
+            params:
+              method string
+                only "linear" is supported
+              limit int
+                the most consecutive missing values to fill
+          isna() Series
+            return a Series of bools for whether each element is missing. isnull is an alias
+          max(skipna?, numeric_only?) any
+            the largest value
+            params:
+              skipna bool
+                skip missing values, default is True. if False, missing values make the result NaN
+              numeric_only bool
+                fail unless the Series is numeric, default is False
+          mean(skipna?, numeric_only?) float
+            the mean
+            params:
+              skipna bool
+                skip missing values, default is True. if False, missing values make the result NaN
+              numeric_only bool
+                fail unless the Series is numeric, default is False
+          median(skipna?, numeric_only?) float
+            the median
+            params:
+              skipna bool
+                skip missing values, default is True. if False, missing values make the result NaN
+              numeric_only bool
+                fail unless the Series is numeric, default is False
+          min(skipna?, numeric_only?) any
+            the smallest value
+            params:
+              skipna bool
+                skip missing values, default is True. if False, missing values make the result NaN
+              numeric_only bool
+                fail unless the Series is numeric, default is False
           notequals(value) Series
             return a Series of bools for whether each element is not equal to the parameter
             params:
               value any
                 value to compare each element to
           notnull() Series
-            return a Series of bools for whether each element is not null
+            return a Series of bools for whether each element is not null. notna is an alias
+          quantile(q?) float,Series
+            the value at a quantile, interpolating between values. A list of quantiles returns a Series indexed by quantile
+            params:
+              q float,list(float)
+                the quantile between 0 and 1, defaulting to 0.5
+          std(skipna?, numeric_only?, ddof?) float
+            the standard deviation
+            params:
+              skipna bool
+                skip missing values, default is True. if False, missing values make the result NaN
+              numeric_only bool
+                fail unless the Series is numeric, default is False
+              ddof int
+                delta degrees of freedom, defaulting to 1
+          sum(skipna?, numeric_only?) any
+            the sum
+            params:
+              skipna bool
+                skip missing values, default is True. if False, missing values make the result NaN
+              numeric_only bool
+                fail unless the Series is numeric, default is False
           unique() Series
             return a Series of just the unique elements
+          var(skipna?, numeric_only?, ddof?) float
+            the variance
+            params:
+              skipna bool
+                skip missing values, default is True. if False, missing values make the result NaN
+              numeric_only bool
+                fail unless the Series is numeric, default is False
+              ddof int
+                delta degrees of freedom, defaulting to 1
+        fields:
+          iloc ILocIndexer
+            returns an ILocIndexer, which retrieves or assigns elements by position, using an int, a list of ints, a slice, or a list or Series of bools
+          loc LocIndexer
+            returns a LocIndexer, which retrieves or assigns elements by label, using a label, a list of labels, a slice, or a list or Series of bools
+
+      SeriesGroupByResult
+        a column of grouped rows. Aggregations return a Series indexed by group
+        methods:
+          agg(func) Series,DataFrame
+            aggregate each group. A single aggregation returns a Series, a list or dict of them returns a DataFrame
+            params:
+              func string,list(string),dict
+                an aggregation, a list of them, or a dict mapping the column name to aggregations
+          apply(function) Series
+            call the function with each group, returning the results as a Series
+            params:
+              function function
+                the function to call with each group
+          count()
+            the number of non-null values in each group
+          first()
+            the first non-null value in each group
+          last()
+            the last non-null value in each group
+          max()
+            the largest value in each group
+          mean()
+            the mean of each group
+          median()
+            the median of each group
+          min()
+            the smallest value in each group
+          nunique()
+            the number of distinct non-null values in each group
+          size()
+            the number of rows in each group
+          std(ddof?)
+            the standard deviation of each group
+            params:
+              ddof int
+                delta degrees of freedom, defaulting to 1
+          sum()
+            the sum of each group
+          var(ddof?)
+            the variance of each group
+            params:
+              ddof int
+                delta degrees of freedom, defaulting to 1
 
       StringMethods
         string functions that will be applied to all strings in the collection
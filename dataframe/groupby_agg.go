@@ -0,0 +1,395 @@
+package dataframe
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"go.starlark.net/starlark"
+)
+
+// errNotNumeric is returned when aggregating values that don't support the
+// aggregation, such as the mean of strings
+var errNotNumeric = errors.New("could not aggregate non-numeric values")
+
+// aggFunc reduces a grouped Series to a single value
+type aggFunc func(s *Series) (interface{}, error)
+
+// aggFuncs are the aggregations that groupby results support, by name. std
+// and var take an argument, see lookupAggFunc
+var aggFuncs = map[string]aggFunc{
+	"count":   aggCount,
+	"first":   aggFirst,
+	"last":    aggLast,
+	"max":     aggMax,
+	"mean":    aggMean,
+	"median":  aggMedian,
+	"min":     aggMin,
+	"nunique": aggNunique,
+	"size":    aggSize,
+	"sum":     aggSum,
+}
+
+// lookupAggFunc returns the aggregation with the given name. std and var use
+// ddof as their delta degrees of freedom
+func lookupAggFunc(name string, ddof int) (aggFunc, error) {
+	switch name {
+	case "std":
+		return func(s *Series) (interface{}, error) { return aggStd(s, ddof) }, nil
+	case "var":
+		return func(s *Series) (interface{}, error) { return aggVar(s, ddof) }, nil
+	}
+	fn, ok := aggFuncs[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown aggregation %q", name)
+	}
+	return fn, nil
+}
+
+// nonNullValues returns the values of a Series, skipping None and NaN
+func nonNullValues(s *Series) []interface{} {
+	vals := make([]interface{}, 0, s.Len())
+	for i := 0; i < s.Len(); i++ {
+		if v := s.At(i); !isNull(v) {
+			vals = append(vals, v)
+		}
+	}
+	return vals
+}
+
+// numericValues converts values to floats, reporting whether every value is
+// an int or bool
+func numericValues(vals []interface{}) ([]float64, bool, error) {
+	nums := make([]float64, len(vals))
+	allInts := true
+	for i, v := range vals {
+		switch x := v.(type) {
+		case int:
+			nums[i] = float64(x)
+		case bool:
+			if x {
+				nums[i] = 1
+			}
+		case float64:
+			nums[i] = x
+			allInts = false
+		default:
+			return nil, false, errNotNumeric
+		}
+	}
+	return nums, allInts, nil
+}
+
+// allStrings reports whether every value is a string
+func allStrings(vals []interface{}) bool {
+	for _, v := range vals {
+		if _, ok := v.(string); !ok {
+			return false
+		}
+	}
+	return len(vals) > 0
+}
+
+func aggCount(s *Series) (interface{}, error) {
+	return len(nonNullValues(s)), nil
+}
+
+func aggSize(s *Series) (interface{}, error) {
+	return s.Len(), nil
+}
+
+func aggNunique(s *Series) (interface{}, error) {
+	seen := map[interface{}]bool{}
+	for _, v := range nonNullValues(s) {
+		seen[v] = true
+	}
+	return len(seen), nil
+}
+
+func aggFirst(s *Series) (interface{}, error) {
+	vals := nonNullValues(s)
+	if len(vals) == 0 {
+		return math.NaN(), nil
+	}
+	return vals[0], nil
+}
+
+func aggLast(s *Series) (interface{}, error) {
+	vals := nonNullValues(s)
+	if len(vals) == 0 {
+		return math.NaN(), nil
+	}
+	return vals[len(vals)-1], nil
+}
+
+// aggSum adds numbers, and concatenates strings like pandas does
+func aggSum(s *Series) (interface{}, error) {
+	vals := nonNullValues(s)
+	if allStrings(vals) {
+		var buf strings.Builder
+		for _, v := range vals {
+			buf.WriteString(v.(string))
+		}
+		return buf.String(), nil
+	}
+	nums, allInts, err := numericValues(vals)
+	if err != nil {
+		return nil, err
+	}
+	if allInts {
+		sum := 0
+		for _, n := range nums {
+			sum += int(n)
+		}
+		return sum, nil
+	}
+	sum := 0.0
+	for _, n := range nums {
+		sum += n
+	}
+	return sum, nil
+}
+
+func aggMean(s *Series) (interface{}, error) {
+	nums, _, err := numericValues(nonNullValues(s))
+	if err != nil {
+		return nil, err
+	}
+	return mean(nums), nil
+}
+
+func aggMedian(s *Series) (interface{}, error) {
+	nums, _, err := numericValues(nonNullValues(s))
+	if err != nil {
+		return nil, err
+	}
+	return median(nums), nil
+}
+
+func aggVar(s *Series, ddof int) (interface{}, error) {
+	nums, _, err := numericValues(nonNullValues(s))
+	if err != nil {
+		return nil, err
+	}
+	return variance(nums, ddof), nil
+}
+
+func aggStd(s *Series, ddof int) (interface{}, error) {
+	nums, _, err := numericValues(nonNullValues(s))
+	if err != nil {
+		return nil, err
+	}
+	return math.Sqrt(variance(nums, ddof)), nil
+}
+
+func aggMin(s *Series) (interface{}, error) {
+	return extreme(nonNullValues(s), -1)
+}
+
+func aggMax(s *Series) (interface{}, error) {
+	return extreme(nonNullValues(s), 1)
+}
+
+// extreme returns the smallest value if sign is -1, or the largest if sign
+// is 1. values keep their type, strings compare lexicographically
+func extreme(vals []interface{}, sign int) (interface{}, error) {
+	if len(vals) == 0 {
+		return math.NaN(), nil
+	}
+	if allStrings(vals) {
+		best := vals[0].(string)
+		for _, v := range vals[1:] {
+			if strings.Compare(v.(string), best) == sign {
+				best = v.(string)
+			}
+		}
+		return best, nil
+	}
+	nums, _, err := numericValues(vals)
+	if err != nil {
+		return nil, err
+	}
+	best := 0
+	for i, n := range nums {
+		if (sign < 0 && n < nums[best]) || (sign > 0 && n > nums[best]) {
+			best = i
+		}
+	}
+	return vals[best], nil
+}
+
+func mean(nums []float64) float64 {
+	if len(nums) == 0 {
+		return math.NaN()
+	}
+	sum := 0.0
+	for _, n := range nums {
+		sum += n
+	}
+	return sum / float64(len(nums))
+}
+
+func median(nums []float64) float64 {
+	if len(nums) == 0 {
+		return math.NaN()
+	}
+	sorted := append([]float64{}, nums...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return sorted[mid]
+	}
+	return (sorted[mid-1] + sorted[mid]) / 2
+}
+
+// variance of the numbers, dividing by the count minus ddof
+func variance(nums []float64, ddof int) float64 {
+	if len(nums)-ddof <= 0 {
+		return math.NaN()
+	}
+	avg := mean(nums)
+	sum := 0.0
+	for _, n := range nums {
+		sum += (n - avg) * (n - avg)
+	}
+	return sum / float64(len(nums)-ddof)
+}
+
+// aggSpec is a column of an aggregation result, made by applying an
+// aggregation to a column of the grouped data
+type aggSpec struct {
+	column string
+	fn     string
+	name   string
+}
+
+// toAggSpecs converts the argument of agg to the columns of the result.
+// A string or list of strings applies each aggregation to every column, a
+// dict maps columns to the aggregations to apply to them. Columns that get
+// a list of aggregations are named "column_aggregation"
+func toAggSpecs(v starlark.Value, columns []string) ([]aggSpec, bool, error) {
+	if fn, ok := toStrMaybe(v); ok {
+		specs := make([]aggSpec, len(columns))
+		for i, col := range columns {
+			specs[i] = aggSpec{column: col, fn: fn, name: col}
+		}
+		return specs, true, nil
+	}
+	if fns := toStrSliceOrNil(v); fns != nil {
+		var specs []aggSpec
+		for _, col := range columns {
+			for _, fn := range fns {
+				specs = append(specs, aggSpec{column: col, fn: fn, name: col + "_" + fn})
+			}
+		}
+		return specs, true, nil
+	}
+	dict, ok := v.(*starlark.Dict)
+	if !ok {
+		return nil, false, fmt.Errorf("agg: func must be a string, list of strings or dict")
+	}
+	var specs []aggSpec
+	for _, item := range dict.Items() {
+		col, ok := toStrMaybe(item[0])
+		if !ok {
+			return nil, false, fmt.Errorf("agg: dict keys must be column names")
+		}
+		if findKeyPos(col, columns) == -1 {
+			return nil, false, fmt.Errorf("agg: column %q not found", col)
+		}
+		if fn, ok := toStrMaybe(item[1]); ok {
+			specs = append(specs, aggSpec{column: col, fn: fn, name: col})
+			continue
+		}
+		fns := toStrSliceOrNil(item[1])
+		if fns == nil {
+			return nil, false, fmt.Errorf("agg: aggregations for column %q must be a string or list of strings", col)
+		}
+		for _, fn := range fns {
+			specs = append(specs, aggSpec{column: col, fn: fn, name: col + "_" + fn})
+		}
+	}
+	return specs, false, nil
+}
+
+// aggColumn is a column of aggregated values, one for each group
+type aggColumn struct {
+	name string
+	vals []interface{}
+}
+
+// aggregate applies an aggregation to each group of a column. series returns
+// the grouped values of the column for the group at position i
+func aggregate(name string, fn aggFunc, numGroups int, series func(i int) *Series) (aggColumn, error) {
+	col := aggColumn{name: name, vals: make([]interface{}, numGroups)}
+	for i := range col.vals {
+		val, err := fn(series(i))
+		if err != nil {
+			return col, err
+		}
+		col.vals[i] = val
+	}
+	return col, nil
+}
+
+// newAggSeries builds a Series of aggregated values, indexed by group
+func newAggSeries(col aggColumn, keys []string, label string) (*Series, error) {
+	builder := newTypedSliceBuilder(len(col.vals))
+	for _, val := range col.vals {
+		builder.push(val)
+	}
+	if err := builder.error(); err != nil {
+		return nil, err
+	}
+	s := builder.toSeries(NewTextIndex(keys, label), col.name)
+	return &s, nil
+}
+
+// newAggDataFrame builds a DataFrame of aggregated columns. if asIndex is
+// true the groups are the index, otherwise they are the first column
+func newAggDataFrame(cols []aggColumn, keys []string, keyVals []interface{}, label string, asIndex bool, outconf *OutputConfig) (*DataFrame, error) {
+	var index *Index
+	if asIndex {
+		index = NewTextIndex(keys, label)
+	} else {
+		cols = append([]aggColumn{{name: label, vals: keyVals}}, cols...)
+	}
+
+	names := make([]string, len(cols))
+	builder := newTableBuilder(len(cols), len(keys))
+	for i := range keys {
+		row := make([]interface{}, len(cols))
+		for j, col := range cols {
+			row[j] = col.vals[i]
+		}
+		builder.pushRow(row)
+	}
+	for j, col := range cols {
+		names[j] = col.name
+	}
+
+	body, err := builder.body()
+	if err != nil {
+		return nil, err
+	}
+	return newDataFrameConstructor(body, NewTextIndex(names, ""), index, outconf)
+}
+
+// unpackAggArgs unpacks the arguments of an aggregation method, returning
+// the delta degrees of freedom that std and var accept
+func unpackAggArgs(b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (int, error) {
+	ddof := 1
+	switch b.Name() {
+	case "std", "var":
+		if err := starlark.UnpackArgs(b.Name(), args, kwargs, "ddof?", &ddof); err != nil {
+			return 0, err
+		}
+	default:
+		if err := starlark.UnpackArgs(b.Name(), args, kwargs); err != nil {
+			return 0, err
+		}
+	}
+	return ddof, nil
+}
@@ -2,6 +2,7 @@ package dataframe
 
 import (
 	"fmt"
+	"sort"
 
 	"go.starlark.net/starlark"
 )
@@ -13,6 +14,9 @@ type GroupByResult struct {
 	grouping map[string][]*rowTuple
 	// index of the source DataFrame
 	dfIndex *Index
+	// whether aggregations use the groups as their index, or as a column
+	asIndex bool
+	outconf *OutputConfig
 }
 
 // compile-time interface assertions
@@ -22,6 +26,22 @@ var (
 	_ starlark.HasAttrs = (*GroupByResult)(nil)
 )
 
+var groupByResultMethods = map[string]*starlark.Builtin{
+	"agg":     starlark.NewBuiltin("agg", groupByResultAgg),
+	"count":   starlark.NewBuiltin("count", groupByResultReduce),
+	"first":   starlark.NewBuiltin("first", groupByResultReduce),
+	"last":    starlark.NewBuiltin("last", groupByResultReduce),
+	"max":     starlark.NewBuiltin("max", groupByResultReduce),
+	"mean":    starlark.NewBuiltin("mean", groupByResultReduce),
+	"median":  starlark.NewBuiltin("median", groupByResultReduce),
+	"min":     starlark.NewBuiltin("min", groupByResultReduce),
+	"nunique": starlark.NewBuiltin("nunique", groupByResultReduce),
+	"size":    starlark.NewBuiltin("size", groupByResultSize),
+	"std":     starlark.NewBuiltin("std", groupByResultReduce),
+	"sum":     starlark.NewBuiltin("sum", groupByResultReduce),
+	"var":     starlark.NewBuiltin("var", groupByResultReduce),
+}
+
 // Freeze has no effect on the immutable GroupByResult
 func (gbr *GroupByResult) Freeze() {
 	// pass
@@ -49,12 +69,12 @@ func (gbr *GroupByResult) Type() string {
 
 // Attr gets a value for an attribute
 func (gbr *GroupByResult) Attr(name string) (starlark.Value, error) {
-	return nil, starlark.NoSuchAttrError(name)
+	return builtinAttr(gbr, name, groupByResultMethods)
 }
 
 // AttrNames lists available attributes
 func (gbr *GroupByResult) AttrNames() []string {
-	return []string{}
+	return builtinAttrNames(groupByResultMethods)
 }
 
 // Get returns a series like object by indexing into the result of a groupBy call
@@ -70,15 +90,147 @@ func (gbr *GroupByResult) Get(key starlark.Value) (value starlark.Value, found b
 	}
 
 	result := make(map[string]*Series, len(gbr.grouping))
-	for group, frame := range gbr.grouping {
-		newRow := []interface{}{}
-		for _, row := range frame {
-			val := row.data[keyPos]
-			newRow = append(newRow, val)
+	keyVals := make(map[string]interface{}, len(gbr.grouping))
+	for group := range gbr.grouping {
+		result[group] = gbr.groupSeries(group, keyPos)
+		keyVals[group] = gbr.keyVal(group)
+	}
+
+	return &SeriesGroupByResult{
+		lhsLabel: gbr.label,
+		rhsLabel: name,
+		grouping: result,
+		keyVals:  keyVals,
+		dfIndex:  gbr.dfIndex,
+		asIndex:  gbr.asIndex,
+		outconf:  gbr.outconf,
+	}, true, nil
+}
+
+// groupSeries returns the values of the column at position pos for a group,
+// named after the group
+func (gbr *GroupByResult) groupSeries(group string, pos int) *Series {
+	newRow := []interface{}{}
+	for _, row := range gbr.grouping[group] {
+		newRow = append(newRow, row.data[pos])
+	}
+	// TODO(dustmop): Set the index
+	return newSeriesConstructor(newRow, nil, group)
+}
+
+// keyVal returns the value of the grouped column for a group
+func (gbr *GroupByResult) keyVal(group string) interface{} {
+	rows := gbr.grouping[group]
+	keyPos := findKeyPos(gbr.label, gbr.columns.Columns())
+	if len(rows) == 0 || keyPos == -1 {
+		return group
+	}
+	return rows[0].data[keyPos]
+}
+
+// sortedKeys returns the groups in sorted order, along with the value of the
+// grouped column for each
+func (gbr *GroupByResult) sortedKeys() ([]string, []interface{}) {
+	keys := make([]string, 0, len(gbr.grouping))
+	for k := range gbr.grouping {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	keyVals := make([]interface{}, len(keys))
+	for i, k := range keys {
+		keyVals[i] = gbr.keyVal(k)
+	}
+	return keys, keyVals
+}
+
+// valueColumns returns the columns that aren't the grouped column
+func (gbr *GroupByResult) valueColumns() []string {
+	var cols []string
+	for _, col := range gbr.columns.Columns() {
+		if col != gbr.label {
+			cols = append(cols, col)
 		}
-		// TODO(dustmop): Set the index
-		result[group] = newSeriesConstructor(newRow, nil, group)
 	}
+	return cols
+}
 
-	return &SeriesGroupByResult{lhsLabel: gbr.label, rhsLabel: name, grouping: result, dfIndex: gbr.dfIndex}, true, nil
+// aggregate applies aggregations to the columns of each group. when dropFailed
+// is true, columns that don't support their aggregation are left out of the
+// result, otherwise they are an error
+func (gbr *GroupByResult) aggregate(specs []aggSpec, dropFailed bool, ddof int) (*DataFrame, error) {
+	keys, keyVals := gbr.sortedKeys()
+	names := gbr.columns.Columns()
+
+	cols := make([]aggColumn, 0, len(specs))
+	for _, spec := range specs {
+		fn, err := lookupAggFunc(spec.fn, ddof)
+		if err != nil {
+			return nil, err
+		}
+		pos := findKeyPos(spec.column, names)
+		col, err := aggregate(spec.name, fn, len(keys), func(i int) *Series {
+			return gbr.groupSeries(keys[i], pos)
+		})
+		if err != nil {
+			if dropFailed && err == errNotNumeric {
+				continue
+			}
+			return nil, fmt.Errorf("%s: column %q: %w", spec.fn, spec.column, err)
+		}
+		cols = append(cols, col)
+	}
+	return newAggDataFrame(cols, keys, keyVals, gbr.label, gbr.asIndex, gbr.outconf)
+}
+
+// reduce methods return a DataFrame that aggregates each column of each group.
+// columns that don't support the aggregation are left out
+func groupByResultReduce(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	self := b.Receiver().(*GroupByResult)
+	ddof, err := unpackAggArgs(b, args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	specs, _, err := toAggSpecs(starlark.String(b.Name()), self.valueColumns())
+	if err != nil {
+		return nil, err
+	}
+	return self.aggregate(specs, true, ddof)
+}
+
+// size method returns a Series of the number of rows in each group
+func groupByResultSize(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if err := starlark.UnpackArgs("size", args, kwargs); err != nil {
+		return nil, err
+	}
+	self := b.Receiver().(*GroupByResult)
+
+	keys, keyVals := self.sortedKeys()
+	col := aggColumn{name: "size", vals: make([]interface{}, len(keys))}
+	for i, k := range keys {
+		col.vals[i] = len(self.grouping[k])
+	}
+	if !self.asIndex {
+		return newAggDataFrame([]aggColumn{col}, keys, keyVals, self.label, false, self.outconf)
+	}
+	col.name = ""
+	return newAggSeries(col, keys, self.label)
+}
+
+// agg method returns a DataFrame made by applying aggregations to columns of
+// each group
+func groupByResultAgg(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var funcVal starlark.Value
+	self := b.Receiver().(*GroupByResult)
+
+	if err := starlark.UnpackArgs("agg", args, kwargs,
+		"func", &funcVal,
+	); err != nil {
+		return nil, err
+	}
+
+	specs, allColumns, err := toAggSpecs(funcVal, self.valueColumns())
+	if err != nil {
+		return nil, err
+	}
+	return self.aggregate(specs, allColumns, 1)
 }
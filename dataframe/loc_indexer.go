@@ -0,0 +1,858 @@
+package dataframe
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"go.starlark.net/lib/time"
+	"go.starlark.net/starlark"
+)
+
+// LocIndexer is returned by the loc attribute of a DataFrame or Series, and
+// selects rows and columns by label
+type LocIndexer struct {
+	locator
+}
+
+// ILocIndexer is returned by the iloc attribute of a DataFrame or Series, and
+// selects rows and columns by integer position
+type ILocIndexer struct {
+	locator
+}
+
+// compile-time interface assertions
+var (
+	_ starlark.Value     = (*LocIndexer)(nil)
+	_ starlark.Mapping   = (*LocIndexer)(nil)
+	_ starlark.HasSetKey = (*LocIndexer)(nil)
+	_ starlark.Value     = (*ILocIndexer)(nil)
+	_ starlark.Mapping   = (*ILocIndexer)(nil)
+	_ starlark.HasSetKey = (*ILocIndexer)(nil)
+	_ starlark.Sliceable = (*ILocIndexer)(nil)
+)
+
+// NewLocIndexer returns a new LocIndexer that selects from the DataFrame
+func NewLocIndexer(owner *DataFrame) *LocIndexer {
+	return &LocIndexer{locator{df: owner}}
+}
+
+// NewILocIndexer returns a new ILocIndexer that selects from the DataFrame
+func NewILocIndexer(owner *DataFrame) *ILocIndexer {
+	return &ILocIndexer{locator{df: owner, positional: true}}
+}
+
+// Freeze has no effect on the LocIndexer
+func (li *LocIndexer) Freeze() {
+}
+
+// Hash cannot be used with LocIndexer
+func (li *LocIndexer) Hash() (uint32, error) {
+	return 0, fmt.Errorf("unhashable: %s", li.Type())
+}
+
+// String returns the indexer as a string
+func (li *LocIndexer) String() string {
+	return "LocIndexer()"
+}
+
+// Truth converts the indexer into a bool
+func (li *LocIndexer) Truth() starlark.Bool {
+	return true
+}
+
+// Type returns the type as a string
+func (li *LocIndexer) Type() string {
+	return fmt.Sprintf("%s.LocIndexer", Name)
+}
+
+// Get selects by label, implements the Mapping interface
+func (li *LocIndexer) Get(key starlark.Value) (starlark.Value, bool, error) {
+	return li.get(key)
+}
+
+// SetKey assigns to the cells selected by label, implements the HasSetKey
+// interface
+func (li *LocIndexer) SetKey(key, val starlark.Value) error {
+	return li.setKey(key, val)
+}
+
+// Freeze has no effect on the ILocIndexer
+func (ii *ILocIndexer) Freeze() {
+}
+
+// Hash cannot be used with ILocIndexer
+func (ii *ILocIndexer) Hash() (uint32, error) {
+	return 0, fmt.Errorf("unhashable: %s", ii.Type())
+}
+
+// String returns the indexer as a string
+func (ii *ILocIndexer) String() string {
+	return "ILocIndexer()"
+}
+
+// Truth converts the indexer into a bool
+func (ii *ILocIndexer) Truth() starlark.Bool {
+	return true
+}
+
+// Type returns the type as a string
+func (ii *ILocIndexer) Type() string {
+	return fmt.Sprintf("%s.ILocIndexer", Name)
+}
+
+// Get selects by position, implements the Mapping interface
+func (ii *ILocIndexer) Get(key starlark.Value) (starlark.Value, bool, error) {
+	return ii.get(key)
+}
+
+// SetKey assigns to the cells selected by position, implements the HasSetKey
+// interface
+func (ii *ILocIndexer) SetKey(key, val starlark.Value) error {
+	return ii.setKey(key, val)
+}
+
+// Len returns the number of rows, implements the Indexable interface
+func (ii *ILocIndexer) Len() int {
+	return ii.rowAxis().size
+}
+
+// Index returns the row at position i, implements the Indexable interface.
+// Like other Indexables i must be in the range [0, Len()). scripts select
+// rows with Get, which reports positions that are out of bounds as errors
+func (ii *ILocIndexer) Index(i int) starlark.Value {
+	if i < 0 || i >= ii.Len() {
+		panic(fmt.Sprintf("iloc: position %d is out of bounds for axis with size %d", i, ii.Len()))
+	}
+	return ii.result(selection{pos: []int{i}, scalar: true}, ii.allColumns())
+}
+
+// Slice returns the rows selected by a slice expression, such as
+// df.iloc[1:3], implements the Sliceable interface. starlark clamps the
+// positions of slice expressions to the rows, so slicing can't fail
+func (ii *ILocIndexer) Slice(start, end, step int) starlark.Value {
+	rows := selection{pos: stepPositions(start, end, step)}
+	return ii.result(rows, ii.allColumns())
+}
+
+// locator selects cells from either a DataFrame or a Series, by label or by
+// position. it implements the shared behavior of LocIndexer and ILocIndexer
+type locator struct {
+	df         *DataFrame
+	series     *Series
+	positional bool
+}
+
+// name of the indexer, for error messages
+func (l *locator) name() string {
+	if l.positional {
+		return "iloc"
+	}
+	return "loc"
+}
+
+// axis is one dimension of a DataFrame or Series, with optional labels
+type axis struct {
+	labels *Index
+	size   int
+}
+
+// labelAt returns the label at position k, which is the position itself if
+// the axis has no labels
+func (a axis) labelAt(k int) string {
+	if a.labels == nil || a.labels.Len() == 0 {
+		return strconv.Itoa(k)
+	}
+	return a.labels.StrAt(k)
+}
+
+// take returns the labels at the given positions as a new Index
+func (a axis) take(pos []int) *Index {
+	identity := len(pos) == a.size
+	for i, p := range pos {
+		if p != i {
+			identity = false
+			break
+		}
+	}
+	if identity {
+		return a.labels
+	}
+	if a.labels == nil || a.labels.Len() == 0 {
+		return NewInt64Index(append([]int{}, pos...), "")
+	}
+	vals := make([]interface{}, len(pos))
+	for i, p := range pos {
+		vals[i] = a.labels.At(p)
+	}
+	return newIndexFrom(vals, a.labels.name)
+}
+
+func (l *locator) rowAxis() axis {
+	if l.series != nil {
+		return axis{labels: l.series.index, size: l.series.Len()}
+	}
+	return axis{labels: l.df.index, size: l.df.NumRows()}
+}
+
+func (l *locator) colAxis() axis {
+	return axis{labels: l.df.columns, size: l.df.NumCols()}
+}
+
+// selection is the list of positions that a key selects along an axis. A
+// scalar key selects a single position and drops the axis from the result
+type selection struct {
+	pos    []int
+	scalar bool
+}
+
+func (l *locator) allColumns() selection {
+	if l.series != nil {
+		return selection{}
+	}
+	return selection{pos: stepPositions(0, l.df.NumCols(), 1)}
+}
+
+// splitKey separates a key into a row key and a column key. A key that isn't
+// a tuple selects rows, and all columns
+func (l *locator) splitKey(key starlark.Value) (starlark.Value, starlark.Value, error) {
+	tup, ok := key.(starlark.Tuple)
+	if !ok {
+		return key, nil, nil
+	}
+	if len(tup) == 2 && l.series == nil {
+		return tup[0], tup[1], nil
+	}
+	if len(tup) == 1 {
+		return tup[0], nil, nil
+	}
+	return nil, nil, fmt.Errorf("%s: too many indexers, got %d", l.name(), len(tup))
+}
+
+// selectAll resolves a key to the rows and columns it selects
+func (l *locator) selectAll(key starlark.Value) (selection, selection, error) {
+	rowKey, colKey, err := l.splitKey(key)
+	if err != nil {
+		return selection{}, selection{}, err
+	}
+	rows, err := l.selectAxis(rowKey, l.rowAxis())
+	if err != nil {
+		return selection{}, selection{}, err
+	}
+	cols := l.allColumns()
+	if colKey != nil {
+		if cols, err = l.selectAxis(colKey, l.colAxis()); err != nil {
+			return selection{}, selection{}, err
+		}
+	}
+	return rows, cols, nil
+}
+
+// selectAxis resolves a key to positions along an axis. Keys may be a single
+// label or position, a list of them, a slice, or a boolean mask
+func (l *locator) selectAxis(key starlark.Value, ax axis) (selection, error) {
+	switch k := key.(type) {
+	case *Slice:
+		pos, err := l.slicePositions(k, ax)
+		return selection{pos: pos}, err
+	case *Series:
+		keys := make([]starlark.Value, k.Len())
+		for i := range keys {
+			keys[i] = k.Index(i)
+		}
+		if k.dtype == "bool" {
+			return l.maskPositions(keys, ax)
+		}
+		return l.listPositions(keys, ax)
+	case *Index:
+		keys := make([]starlark.Value, k.Len())
+		for i := range keys {
+			keys[i] = starlark.String(k.StrAt(i))
+		}
+		return l.listPositions(keys, ax)
+	case starlark.String, starlark.Int, starlark.Float, starlark.Bool:
+		pos, err := l.keyPositions(k, ax)
+		if err != nil {
+			return selection{}, err
+		}
+		return selection{pos: pos, scalar: len(pos) == 1}, nil
+	case starlark.Indexable:
+		keys := make([]starlark.Value, k.Len())
+		isMask := k.Len() > 0
+		for i := range keys {
+			keys[i] = k.Index(i)
+			if _, ok := keys[i].(starlark.Bool); !ok {
+				isMask = false
+			}
+		}
+		if isMask {
+			return l.maskPositions(keys, ax)
+		}
+		return l.listPositions(keys, ax)
+	}
+	return selection{}, fmt.Errorf("%s: invalid key %s of type %s", l.name(), key, key.Type())
+}
+
+// keyPositions returns the positions a single label or position selects.
+// Labels may appear more than once in an index
+func (l *locator) keyPositions(key starlark.Value, ax axis) ([]int, error) {
+	if l.positional {
+		i, err := starlark.AsInt32(key)
+		if err != nil {
+			return nil, fmt.Errorf("iloc: positions must be integers, got %s", key.Type())
+		}
+		orig := i
+		if i < 0 {
+			i += ax.size
+		}
+		if i < 0 || i >= ax.size {
+			return nil, fmt.Errorf("iloc: position %d is out of bounds for axis with size %d", orig, ax.size)
+		}
+		return []int{i}, nil
+	}
+
+	label := toStr(key)
+	var pos []int
+	for k := 0; k < ax.size; k++ {
+		if ax.labelAt(k) == label {
+			pos = append(pos, k)
+		}
+	}
+	if pos == nil {
+		return nil, fmt.Errorf("loc: label %s not found", key)
+	}
+	return pos, nil
+}
+
+// listPositions returns the positions selected by a list of keys, in order
+func (l *locator) listPositions(keys []starlark.Value, ax axis) (selection, error) {
+	var pos []int
+	for _, key := range keys {
+		ps, err := l.keyPositions(key, ax)
+		if err != nil {
+			return selection{}, err
+		}
+		pos = append(pos, ps...)
+	}
+	return selection{pos: pos}, nil
+}
+
+// maskPositions returns the positions where a mask of bools is True
+func (l *locator) maskPositions(mask []starlark.Value, ax axis) (selection, error) {
+	if len(mask) != ax.size {
+		return selection{}, fmt.Errorf("%s: boolean index has wrong length %d instead of %d", l.name(), len(mask), ax.size)
+	}
+	pos := []int{}
+	for i, b := range mask {
+		if b.Truth() {
+			pos = append(pos, i)
+		}
+	}
+	return selection{pos: pos}, nil
+}
+
+// slicePositions returns the positions a slice selects. Positional slices
+// exclude their stop position, label slices include their stop label
+func (l *locator) slicePositions(s *Slice, ax axis) ([]int, error) {
+	step := 1
+	if s.step != starlark.None {
+		var err error
+		if step, err = starlark.AsInt32(s.step); err != nil || step == 0 {
+			return nil, fmt.Errorf("%s: slice step must be a non-zero integer, got %s", l.name(), s.step)
+		}
+	}
+
+	if l.positional {
+		start, end := 0, ax.size
+		if step < 0 {
+			start, end = ax.size-1, -1
+		}
+		if s.start != starlark.None {
+			n, err := starlark.AsInt32(s.start)
+			if err != nil {
+				return nil, fmt.Errorf("iloc: slice start must be an integer, got %s", s.start.Type())
+			}
+			start = clampSliceIndex(n, ax.size, step)
+		}
+		if s.stop != starlark.None {
+			n, err := starlark.AsInt32(s.stop)
+			if err != nil {
+				return nil, fmt.Errorf("iloc: slice stop must be an integer, got %s", s.stop.Type())
+			}
+			end = clampSliceIndex(n, ax.size, step)
+		}
+		return stepPositions(start, end, step), nil
+	}
+
+	// Label slices run from the first position of the start label to the
+	// last position of the stop label
+	start, end := 0, ax.size-1
+	if step < 0 {
+		start, end = ax.size-1, 0
+	}
+	if s.start != starlark.None {
+		pos, err := l.keyPositions(s.start, ax)
+		if err != nil {
+			return nil, err
+		}
+		start = pos[0]
+	}
+	if s.stop != starlark.None {
+		pos, err := l.keyPositions(s.stop, ax)
+		if err != nil {
+			return nil, err
+		}
+		end = pos[len(pos)-1]
+	}
+	if step > 0 {
+		return stepPositions(start, end+1, step), nil
+	}
+	return stepPositions(start, end-1, step), nil
+}
+
+// clampSliceIndex resolves a negative slice index and clamps it to the
+// valid range, following python's rules
+func clampSliceIndex(n, size, step int) int {
+	if n < 0 {
+		n += size
+	}
+	lo, hi := 0, size
+	if step < 0 {
+		lo, hi = -1, size-1
+	}
+	if n < lo {
+		return lo
+	} else if n > hi {
+		return hi
+	}
+	return n
+}
+
+// stepPositions lists positions from start up to, but excluding, end
+func stepPositions(start, end, step int) []int {
+	pos := []int{}
+	if step > 0 {
+		for i := start; i < end; i += step {
+			pos = append(pos, i)
+		}
+	} else {
+		for i := start; i > end; i += step {
+			pos = append(pos, i)
+		}
+	}
+	return pos
+}
+
+func (l *locator) get(key starlark.Value) (starlark.Value, bool, error) {
+	rows, cols, err := l.selectAll(key)
+	if err != nil {
+		return nil, false, err
+	}
+	return l.result(rows, cols), true, nil
+}
+
+// result builds the value for a selection: a single cell, a Series, or a
+// DataFrame. selections are resolved to positions within the axes, so
+// building the result can't fail
+func (l *locator) result(rows, cols selection) starlark.Value {
+	rowAxis := l.rowAxis()
+	if l.series != nil {
+		if rows.scalar {
+			return cellToStarlark(l.series.At(rows.pos[0]))
+		}
+		return l.series.take(rows.pos, rowAxis.take(rows.pos))
+	}
+
+	colAxis := l.colAxis()
+	switch {
+	case rows.scalar && cols.scalar:
+		return cellToStarlark(l.df.body[cols.pos[0]].At(rows.pos[0]))
+	case cols.scalar:
+		c := cols.pos[0]
+		series := l.df.body[c].take(rows.pos, rowAxis.take(rows.pos))
+		series.name = colAxis.labelAt(c)
+		return series
+	case rows.scalar:
+		return l.row(rows.pos[0], cols.pos)
+	}
+
+	body := make([]Series, len(cols.pos))
+	for j, c := range cols.pos {
+		body[j] = *l.df.body[c].take(rows.pos, nil)
+	}
+	return &DataFrame{
+		body:    body,
+		columns: colAxis.take(cols.pos),
+		index:   rowAxis.take(rows.pos),
+		outconf: l.df.outconf,
+	}
+}
+
+// row returns the cells of row r in the columns at positions cols as a
+// Series. rows with cells that don't share a type, such as lists assigned to
+// a single cell, are Series of objects
+func (l *locator) row(r int, cols []int) *Series {
+	index, name := l.colAxis().take(cols), l.rowAxis().labelAt(r)
+	builder := newTypedSliceBuilder(len(cols))
+	for _, c := range cols {
+		builder.push(l.df.body[c].At(r))
+	}
+	if builder.error() == nil {
+		series := builder.toSeries(index, name)
+		return &series
+	}
+	vals := make([]interface{}, len(cols))
+	for i, c := range cols {
+		vals[i] = l.df.body[c].At(r)
+	}
+	return &Series{dtype: "object", which: typeObj, valObjs: vals, index: index, name: name}
+}
+
+// setKey assigns a value to the selected cells. Scalars are assigned to every
+// cell, lists and Series must have one value per selected row, or per
+// selected column if a single row is selected. Selections of both several
+// rows and columns also accept a DataFrame or a list of lists
+func (l *locator) setKey(key, val starlark.Value) error {
+	if (l.df != nil && l.df.frozen) || (l.series != nil && l.series.frozen) {
+		return fmt.Errorf("%s: cannot set, %s is frozen", l.name(), l.ownerType())
+	}
+	rows, cols, err := l.selectAll(key)
+	if err != nil {
+		return err
+	}
+
+	if l.series != nil {
+		vals, err := l.assignValues(val, len(rows.pos), rows.scalar)
+		if err != nil {
+			return err
+		}
+		l.series.cloneValues()
+		for i, r := range rows.pos {
+			l.series.setValue(r, vals[i])
+		}
+		return nil
+	}
+
+	// grid holds the value to assign to each selected cell, by row then column
+	grid := make([][]interface{}, len(rows.pos))
+	switch {
+	case rows.scalar || cols.scalar:
+		n := len(cols.pos)
+		if cols.scalar {
+			n = len(rows.pos)
+		}
+		vals, err := l.assignValues(val, n, rows.scalar && cols.scalar)
+		if err != nil {
+			return err
+		}
+		for i := range rows.pos {
+			grid[i] = make([]interface{}, len(cols.pos))
+			for j := range cols.pos {
+				if cols.scalar {
+					grid[i][j] = vals[i]
+				} else {
+					grid[i][j] = vals[j]
+				}
+			}
+		}
+	default:
+		if grid, err = l.assignGrid(val, len(rows.pos), len(cols.pos)); err != nil {
+			return err
+		}
+	}
+
+	// DataFrames made from this one share its columns, copy the columns
+	// before assigning so they don't change
+	body := append([]Series{}, l.df.body...)
+	for j, c := range cols.pos {
+		body[c].cloneValues()
+		for i, r := range rows.pos {
+			body[c].setValue(r, grid[i][j])
+		}
+	}
+	l.df.body = body
+	return nil
+}
+
+func (l *locator) ownerType() string {
+	if l.series != nil {
+		return "Series"
+	}
+	return "DataFrame"
+}
+
+// assignValues converts the value of an assignment to n go native values. a
+// single cell may be assigned any value, including a list
+func (l *locator) assignValues(val starlark.Value, n int, cell bool) ([]interface{}, error) {
+	vals := make([]interface{}, n)
+	if cell {
+		vals[0] = toNativeValue(val)
+		return vals, nil
+	}
+
+	switch x := val.(type) {
+	case *Series:
+		if x.Len() != n {
+			return nil, fmt.Errorf("%s: cannot assign %d values to %d selected cells", l.name(), x.Len(), n)
+		}
+		for i := range vals {
+			vals[i] = x.At(i)
+		}
+		return vals, nil
+	case *starlark.List, starlark.Tuple:
+		seq := x.(starlark.Indexable)
+		if seq.Len() != n {
+			return nil, fmt.Errorf("%s: cannot assign %d values to %d selected cells", l.name(), seq.Len(), n)
+		}
+		for i := range vals {
+			vals[i] = toNativeValue(seq.Index(i))
+		}
+		return vals, nil
+	}
+
+	if _, ok := toScalarMaybe(val); !ok && val != starlark.None {
+		return nil, fmt.Errorf("%s: cannot assign value of type %s", l.name(), val.Type())
+	}
+	item := toNativeValue(val)
+	for i := range vals {
+		vals[i] = item
+	}
+	return vals, nil
+}
+
+// assignGrid converts the value of an assignment to a grid of go native
+// values with the given number of rows and columns
+func (l *locator) assignGrid(val starlark.Value, numRows, numCols int) ([][]interface{}, error) {
+	grid := make([][]interface{}, numRows)
+	switch x := val.(type) {
+	case *DataFrame:
+		if x.NumRows() != numRows || x.NumCols() != numCols {
+			return nil, fmt.Errorf("%s: cannot assign DataFrame of shape (%d, %d) to selection of shape (%d, %d)", l.name(), x.NumRows(), x.NumCols(), numRows, numCols)
+		}
+		for i := range grid {
+			grid[i] = x.Row(i)
+		}
+		return grid, nil
+	case *starlark.List, starlark.Tuple:
+		seq := x.(starlark.Indexable)
+		if seq.Len() != numRows {
+			return nil, fmt.Errorf("%s: cannot assign %d rows to %d selected rows", l.name(), seq.Len(), numRows)
+		}
+		for i := range grid {
+			row, err := l.assignValues(seq.Index(i), numCols, false)
+			if err != nil {
+				return nil, err
+			}
+			grid[i] = row
+		}
+		return grid, nil
+	}
+
+	row, err := l.assignValues(val, numCols, false)
+	if err != nil {
+		return nil, err
+	}
+	for i := range grid {
+		grid[i] = row
+	}
+	return grid, nil
+}
+
+// cellToStarlark converts a cell to a starlark value, missing values are None.
+// cells hold the values toNativeValue makes, including lists and dicts
+// assigned to a single cell
+func cellToStarlark(cell interface{}) starlark.Value {
+	switch x := cell.(type) {
+	case nil:
+		return starlark.None
+	case starlark.Value:
+		return x
+	case int64:
+		return starlark.MakeInt64(x)
+	case []interface{}:
+		elems := make([]starlark.Value, len(x))
+		for i, elem := range x {
+			elems[i] = cellToStarlark(elem)
+		}
+		return starlark.NewList(elems)
+	case map[string]interface{}:
+		keys := make([]string, 0, len(x))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		d := starlark.NewDict(len(x))
+		for _, k := range keys {
+			d.SetKey(starlark.String(k), cellToStarlark(x[k]))
+		}
+		return d
+	}
+	if v, err := convertToStarlark(cell); err == nil {
+		return v
+	}
+	return starlark.String(fmt.Sprintf("%v", cell))
+}
+
+// take returns a new Series of the values at the given positions
+func (s *Series) take(pos []int, index *Index) *Series {
+	res := &Series{which: s.which, dtype: s.dtype, index: index, name: s.name}
+	switch s.which {
+	case typeInt:
+		res.valInts = make([]int, len(pos))
+		for i, p := range pos {
+			res.valInts[i] = s.valInts[p]
+		}
+		if res.dtype == "" {
+			res.dtype = "int64"
+		}
+	case typeFloat:
+		res.valFloats = make([]float64, len(pos))
+		for i, p := range pos {
+			res.valFloats[i] = s.valFloats[p]
+		}
+		if res.dtype == "" {
+			res.dtype = "float64"
+		}
+	default:
+		res.which = typeObj
+		res.valObjs = make([]interface{}, len(pos))
+		for i, p := range pos {
+			res.valObjs[i] = s.valObjs[p]
+		}
+		if res.dtype == "" {
+			res.dtype = "object"
+		}
+	}
+	return res
+}
+
+// setValue assigns a go native value to the cell at position i, converting
+// the storage of the Series if the value doesn't fit it. Assigning a float or
+// None to a Series of ints converts it to floats, other mismatches convert it
+// to objects
+func (s *Series) setValue(i int, val interface{}) {
+	if n, ok := val.(int64); ok {
+		val = int(n)
+	}
+	special := s.dtype == "bool" || s.dtype == "datetime64[ns]" || s.dtype == "timedelta64[ns]"
+
+	switch s.which {
+	case typeInt:
+		switch x := val.(type) {
+		case int:
+			if !special {
+				s.valInts[i] = x
+				return
+			}
+		case bool:
+			if s.dtype == "bool" {
+				s.valInts[i] = 0
+				if x {
+					s.valInts[i] = 1
+				}
+				return
+			}
+		case time.Time:
+			if s.dtype == "datetime64[ns]" {
+				s.valInts[i] = timeToInt(x)
+				return
+			}
+		case float64, nil:
+			if !special {
+				s.valFloats = convertIntsToFloats(s.valInts)
+				s.valInts = nil
+				s.which = typeFloat
+				s.dtype = "float64"
+				s.setValue(i, val)
+				return
+			}
+		}
+	case typeFloat:
+		switch x := val.(type) {
+		case float64:
+			s.valFloats[i] = x
+			return
+		case int:
+			s.valFloats[i] = float64(x)
+			return
+		case nil:
+			s.valFloats[i] = math.NaN()
+			return
+		}
+	}
+
+	if s.which != typeObj {
+		objs := make([]interface{}, s.Len())
+		for k := range objs {
+			objs[k] = s.At(k)
+		}
+		s.valObjs = objs
+		s.valInts = nil
+		s.valFloats = nil
+		s.which = typeObj
+		s.dtype = "object"
+	}
+	s.valObjs[i] = val
+}
+
+// cloneValues replaces the storage of the Series with a copy, so that
+// assigning to it doesn't change other Series and DataFrames that share the
+// storage
+func (s *Series) cloneValues() {
+	switch s.which {
+	case typeInt:
+		s.valInts = append([]int{}, s.valInts...)
+	case typeFloat:
+		s.valFloats = append([]float64{}, s.valFloats...)
+	default:
+		s.valObjs = append([]interface{}{}, s.valObjs...)
+	}
+}
+
+// Slice is a range of labels or positions used as a key of loc or iloc,
+// constructed by dataframe.slice. Unlike slice expressions, such as
+// df.iloc[1:3], a Slice can select columns: df.loc[:, dataframe.slice("a", "c")]
+type Slice struct {
+	start, stop, step starlark.Value
+}
+
+// compile-time interface assertions
+var _ starlark.Value = (*Slice)(nil)
+
+// Freeze has no effect on the immutable Slice
+func (s *Slice) Freeze() {
+}
+
+// Hash cannot be used with Slice
+func (s *Slice) Hash() (uint32, error) {
+	return 0, fmt.Errorf("unhashable: %s", s.Type())
+}
+
+// String returns the slice as a string
+func (s *Slice) String() string {
+	return fmt.Sprintf("slice(%s, %s, %s)", s.start, s.stop, s.step)
+}
+
+// Truth converts the slice into a bool
+func (s *Slice) Truth() starlark.Bool {
+	return true
+}
+
+// Type returns the type as a string
+func (s *Slice) Type() string {
+	return fmt.Sprintf("%s.slice", Name)
+}
+
+// newSlice constructs a Slice like python's slice builtin: slice(stop) or
+// slice(start, stop, step?)
+func newSlice(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var start, stop, step starlark.Value = starlark.None, starlark.None, starlark.None
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &start, &stop, &step); err != nil {
+		return nil, err
+	}
+	if len(args) == 1 {
+		start, stop = starlark.None, start
+	}
+	return &Slice{start: start, stop: stop, step: step}, nil
+}
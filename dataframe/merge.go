@@ -0,0 +1,474 @@
+package dataframe
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.starlark.net/starlark"
+)
+
+// mergeSide is one of the DataFrames being merged, along with the columns, or
+// the index, holding its keys
+type mergeSide struct {
+	df    *DataFrame
+	cols  []int
+	index bool
+}
+
+// key returns the key of row i as a string for matching rows, and as values
+// for sorting keys
+func (m *mergeSide) key(i int) (string, []interface{}) {
+	if m.index {
+		if m.df.index == nil || m.df.index.Len() == 0 {
+			return strconv.Itoa(i), []interface{}{i}
+		}
+		return m.df.index.StrAt(i), []interface{}{m.df.index.At(i)}
+	}
+	strs := make([]string, len(m.cols))
+	vals := make([]interface{}, len(m.cols))
+	for j, c := range m.cols {
+		strs[j] = m.df.body[c].StrAt(i)
+		vals[j] = m.df.body[c].At(i)
+	}
+	return strings.Join(strs, "\x00"), vals
+}
+
+// mergeGroups holds the rows of a DataFrame that share each key
+type mergeGroups struct {
+	// keys in order of first appearance
+	keys []string
+	rows map[string][]int
+	vals map[string][]interface{}
+}
+
+func (m *mergeSide) groups() *mergeGroups {
+	g := &mergeGroups{rows: map[string][]int{}, vals: map[string][]interface{}{}}
+	for i := 0; i < m.df.NumRows(); i++ {
+		k, vals := m.key(i)
+		if _, ok := g.rows[k]; !ok {
+			g.keys = append(g.keys, k)
+			g.vals[k] = vals
+		}
+		g.rows[k] = append(g.rows[k], i)
+	}
+	return g
+}
+
+// unique returns an error if any key appears in more than one row
+func (g *mergeGroups) unique(side, kind string) error {
+	for _, k := range g.keys {
+		if len(g.rows[k]) > 1 {
+			return fmt.Errorf("merge keys are not unique in %s dataset; not a %s merge", side, kind)
+		}
+	}
+	return nil
+}
+
+// mergePair is a row of a merge result, made of a left row and a right row.
+// rows missing from one side are -1
+type mergePair struct {
+	left, right int
+}
+
+// merge method merges the rows of two DataFrames, joining rows that have
+// equal keys
+func dataframeMerge(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var (
+		right, leftOn, rightOn, how starlark.Value
+		suffixesVal, onVal          starlark.Value
+		leftIndex, rightIndex       bool
+		indicatorVal, validateVal   starlark.Value
+		self                        = b.Receiver().(*DataFrame)
+	)
+
+	if err := starlark.UnpackArgs("merge", args, kwargs,
+		"right", &right,
+		"left_on?", &leftOn,
+		"right_on?", &rightOn,
+		"how?", &how,
+		"suffixes?", &suffixesVal,
+		"on?", &onVal,
+		"left_index?", &leftIndex,
+		"right_index?", &rightIndex,
+		"indicator?", &indicatorVal,
+		"validate?", &validateVal,
+	); err != nil {
+		return nil, err
+	}
+
+	rightFrame, ok := right.(*DataFrame)
+	if !ok {
+		return starlark.None, fmt.Errorf("`right` must be a DataFrame")
+	}
+
+	howStr := toStrOrEmpty(how)
+	switch howStr {
+	case "":
+		howStr = "inner"
+	case "inner", "left", "right", "outer", "cross":
+	default:
+		return starlark.None, fmt.Errorf("invalid `how` %q, expected one of \"inner\", \"left\", \"right\", \"outer\" or \"cross\"", howStr)
+	}
+
+	// Default column suffixes are "_x" and "_y"
+	suffixes := []string{"_x", "_y"}
+	if suffixesVal != nil && suffixesVal != starlark.None {
+		if suffixes = toStrSliceOrNil(suffixesVal); len(suffixes) != 2 {
+			return starlark.None, fmt.Errorf("`suffixes` must be a list of 2 strings")
+		}
+	}
+
+	indicator := ""
+	if name, ok := toStrMaybe(indicatorVal); ok {
+		indicator = name
+	} else if set, ok := toBoolMaybe(indicatorVal); ok && set {
+		indicator = "_merge"
+	}
+
+	leftNames := self.columnNames()
+	rightNames := rightFrame.columnNames()
+	leftSide := &mergeSide{df: self, index: leftIndex}
+	rightSide := &mergeSide{df: rightFrame, index: rightIndex}
+
+	on := toStrOrStrSliceOrNil(onVal)
+	leftOnNames := toStrOrStrSliceOrNil(leftOn)
+	rightOnNames := toStrOrStrSliceOrNil(rightOn)
+
+	if howStr == "cross" {
+		if on != nil || leftOnNames != nil || rightOnNames != nil || leftIndex || rightIndex {
+			return starlark.None, fmt.Errorf("cannot combine how=\"cross\" with on, left_on, right_on, left_index or right_index")
+		}
+	} else {
+		if on != nil && (leftOnNames != nil || rightOnNames != nil || leftIndex || rightIndex) {
+			return starlark.None, fmt.Errorf("can only pass `on`, or `left_on` and `right_on` or `left_index` and `right_index`, not a combination")
+		}
+		if on == nil && leftOnNames == nil && rightOnNames == nil && !leftIndex && !rightIndex {
+			// Merge on the first column of each DataFrame
+			if len(leftNames) == 0 || len(rightNames) == 0 {
+				return starlark.None, fmt.Errorf("cannot merge a DataFrame without columns")
+			}
+			leftOnNames, rightOnNames = leftNames[:1], rightNames[:1]
+		}
+		if on != nil {
+			leftOnNames, rightOnNames = on, on
+		}
+		if leftOnNames == nil && !leftIndex {
+			return starlark.None, fmt.Errorf("must pass `left_on` or `left_index=True`")
+		}
+		if rightOnNames == nil && !rightIndex {
+			return starlark.None, fmt.Errorf("must pass `right_on` or `right_index=True`")
+		}
+
+		var err error
+		if !leftIndex {
+			if leftSide.cols, err = keyPositions(leftOnNames, leftNames, "left"); err != nil {
+				return starlark.None, err
+			}
+		}
+		if !rightIndex {
+			if rightSide.cols, err = keyPositions(rightOnNames, rightNames, "right"); err != nil {
+				return starlark.None, err
+			}
+		}
+		numLeft, numRight := len(leftSide.cols), len(rightSide.cols)
+		if leftIndex {
+			numLeft = 1
+		}
+		if rightIndex {
+			numRight = 1
+		}
+		if numLeft != numRight {
+			return starlark.None, fmt.Errorf("number of left keys %d does not match number of right keys %d", numLeft, numRight)
+		}
+	}
+
+	pairs, err := mergePairs(leftSide, rightSide, howStr, toStrOrEmpty(validateVal))
+	if err != nil {
+		return starlark.None, err
+	}
+
+	// Key columns with the same name on both sides only appear once, taking
+	// the value from whichever side has the row
+	shared := map[int]int{}
+	rightShared := map[int]bool{}
+	if !leftIndex && !rightIndex {
+		for i, lc := range leftSide.cols {
+			rc := rightSide.cols[i]
+			if leftNames[lc] == rightNames[rc] {
+				shared[lc] = rc
+				rightShared[rc] = true
+			}
+		}
+	}
+	var rightCols []int
+	for rc := range rightNames {
+		if !rightShared[rc] {
+			rightCols = append(rightCols, rc)
+		}
+	}
+
+	// Other columns that appear on both sides get suffixes
+	newColumns := make([]string, 0, len(leftNames)+len(rightCols)+1)
+	for lc, name := range leftNames {
+		if _, ok := shared[lc]; !ok && containsColumn(rightNames, rightCols, name) {
+			name += suffixes[0]
+		}
+		newColumns = append(newColumns, name)
+	}
+	for _, rc := range rightCols {
+		name := rightNames[rc]
+		if containsColumn(leftNames, nil, name) {
+			if _, ok := shared[findKeyPos(name, leftNames)]; !ok {
+				name += suffixes[1]
+			}
+		}
+		newColumns = append(newColumns, name)
+	}
+	if indicator != "" {
+		newColumns = append(newColumns, indicator)
+	}
+
+	var indexVals []interface{}
+	builder := newTableBuilder(len(newColumns), len(pairs))
+	for _, p := range pairs {
+		row := make([]interface{}, 0, len(newColumns))
+		for lc := range leftNames {
+			if p.left != -1 {
+				row = append(row, self.body[lc].At(p.left))
+			} else if rc, ok := shared[lc]; ok {
+				row = append(row, rightFrame.body[rc].At(p.right))
+			} else {
+				row = append(row, self.body[lc].missing())
+			}
+		}
+		for _, rc := range rightCols {
+			if p.right != -1 {
+				row = append(row, rightFrame.body[rc].At(p.right))
+			} else {
+				row = append(row, rightFrame.body[rc].missing())
+			}
+		}
+		if indicator != "" {
+			switch {
+			case p.left == -1:
+				row = append(row, "right_only")
+			case p.right == -1:
+				row = append(row, "left_only")
+			default:
+				row = append(row, "both")
+			}
+		}
+		builder.pushRow(row)
+
+		if leftIndex && rightIndex {
+			if p.left != -1 {
+				indexVals = append(indexVals, self.index.At(p.left))
+			} else {
+				indexVals = append(indexVals, rightFrame.index.At(p.right))
+			}
+		}
+	}
+
+	// Finish building the body, return any errors
+	body, err := builder.body()
+	if err != nil {
+		return nil, err
+	}
+
+	// Merging both indexes keeps the index, otherwise the result gets a new one
+	var index *Index
+	if leftIndex && rightIndex {
+		name := ""
+		if self.index != nil {
+			name = self.index.name
+		}
+		index = newIndexFrom(indexVals, name)
+	}
+	return newDataFrameConstructor(body, NewTextIndex(newColumns, ""), index, self.outconf)
+}
+
+// mergePairs determines the rows of a merge result. inner merges group rows
+// by key, in the order keys first appear in the left DataFrame. left and
+// right merges keep the order of the left or right DataFrame, outer merges
+// sort by key
+func mergePairs(left, right *mergeSide, how, validate string) ([]mergePair, error) {
+	var pairs []mergePair
+	if how == "cross" {
+		for l := 0; l < left.df.NumRows(); l++ {
+			for r := 0; r < right.df.NumRows(); r++ {
+				pairs = append(pairs, mergePair{l, r})
+			}
+		}
+		return pairs, nil
+	}
+
+	leftGroups := left.groups()
+	rightGroups := right.groups()
+
+	switch validate {
+	case "", "many_to_many", "m:m":
+	case "one_to_one", "1:1":
+		if err := leftGroups.unique("left", "one-to-one"); err != nil {
+			return nil, err
+		}
+		if err := rightGroups.unique("right", "one-to-one"); err != nil {
+			return nil, err
+		}
+	case "one_to_many", "1:m":
+		if err := leftGroups.unique("left", "one-to-many"); err != nil {
+			return nil, err
+		}
+	case "many_to_one", "m:1":
+		if err := rightGroups.unique("right", "many-to-one"); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("invalid `validate` %q, expected one of \"one_to_one\", \"one_to_many\", \"many_to_one\" or \"many_to_many\"", validate)
+	}
+
+	switch how {
+	case "inner":
+		for _, k := range leftGroups.keys {
+			for _, l := range leftGroups.rows[k] {
+				for _, r := range rightGroups.rows[k] {
+					pairs = append(pairs, mergePair{l, r})
+				}
+			}
+		}
+	case "left":
+		for l := 0; l < left.df.NumRows(); l++ {
+			k, _ := left.key(l)
+			rs := rightGroups.rows[k]
+			if len(rs) == 0 {
+				pairs = append(pairs, mergePair{l, -1})
+			}
+			for _, r := range rs {
+				pairs = append(pairs, mergePair{l, r})
+			}
+		}
+	case "right":
+		for r := 0; r < right.df.NumRows(); r++ {
+			k, _ := right.key(r)
+			ls := leftGroups.rows[k]
+			if len(ls) == 0 {
+				pairs = append(pairs, mergePair{-1, r})
+			}
+			for _, l := range ls {
+				pairs = append(pairs, mergePair{l, r})
+			}
+		}
+	case "outer":
+		keys := append([]string{}, leftGroups.keys...)
+		vals := leftGroups.vals
+		for _, k := range rightGroups.keys {
+			if _, ok := leftGroups.rows[k]; !ok {
+				keys = append(keys, k)
+				vals[k] = rightGroups.vals[k]
+			}
+		}
+		sort.SliceStable(keys, func(i, j int) bool {
+			return compareKeys(vals[keys[i]], vals[keys[j]]) < 0
+		})
+		for _, k := range keys {
+			ls, rs := leftGroups.rows[k], rightGroups.rows[k]
+			switch {
+			case len(rs) == 0:
+				for _, l := range ls {
+					pairs = append(pairs, mergePair{l, -1})
+				}
+			case len(ls) == 0:
+				for _, r := range rs {
+					pairs = append(pairs, mergePair{-1, r})
+				}
+			default:
+				for _, l := range ls {
+					for _, r := range rs {
+						pairs = append(pairs, mergePair{l, r})
+					}
+				}
+			}
+		}
+	}
+	return pairs, nil
+}
+
+// compareKeys orders keys value by value. numbers compare numerically, other
+// values compare as strings
+func compareKeys(a, b []interface{}) int {
+	for i := range a {
+		x, xok := toFloat(a[i])
+		y, yok := toFloat(b[i])
+		if xok && yok {
+			if x < y {
+				return -1
+			} else if x > y {
+				return 1
+			}
+			continue
+		}
+		if c := strings.Compare(fmt.Sprintf("%v", a[i]), fmt.Sprintf("%v", b[i])); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// toFloat converts a go native number to a float
+func toFloat(v interface{}) (float64, bool) {
+	switch x := v.(type) {
+	case int:
+		return float64(x), true
+	case float64:
+		return x, true
+	}
+	return 0, false
+}
+
+// keyPositions finds the position of each key column
+func keyPositions(keys, names []string, side string) ([]int, error) {
+	pos := make([]int, len(keys))
+	for i, key := range keys {
+		if pos[i] = findKeyPos(key, names); pos[i] == -1 {
+			return nil, fmt.Errorf("%s key %q not found", side, key)
+		}
+	}
+	return pos, nil
+}
+
+// containsColumn reports whether one of the columns at the given positions
+// has the name. nil positions checks every column
+func containsColumn(names []string, pos []int, name string) bool {
+	if pos == nil {
+		return findKeyPos(name, names) != -1
+	}
+	for _, p := range pos {
+		if names[p] == name {
+			return true
+		}
+	}
+	return false
+}
+
+// columnNames returns the names of the columns, which are their positions if
+// the DataFrame has no column names
+func (df *DataFrame) columnNames() []string {
+	if df.columns == nil || df.columns.Len() == 0 {
+		names := make([]string, df.NumCols())
+		for i := range names {
+			names[i] = strconv.Itoa(i)
+		}
+		return names
+	}
+	return df.columns.Columns()
+}
+
+// convert starlark value to a list of strings, either from a single string or
+// a list of strings, or nil if not possible
+func toStrOrStrSliceOrNil(v starlark.Value) []string {
+	if text, ok := toStrMaybe(v); ok {
+		return []string{text}
+	}
+	return toStrSliceOrNil(v)
+}
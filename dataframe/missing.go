@@ -0,0 +1,596 @@
+package dataframe
+
+import (
+	"fmt"
+	"math"
+
+	"go.starlark.net/starlark"
+)
+
+// isNull reports whether a go native value is missing, meaning it is None or
+// NaN
+func isNull(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	f, ok := v.(float64)
+	return ok && math.IsNaN(f)
+}
+
+// isNullAt reports whether the value at position i is missing. Series that
+// store ints never have missing values
+func (s *Series) isNullAt(i int) bool {
+	switch s.which {
+	case typeInt:
+		return false
+	case typeFloat:
+		return math.IsNaN(s.valFloats[i])
+	}
+	return isNull(s.valObjs[i])
+}
+
+// missing returns the value of a missing cell of the Series. cells of
+// numbers are NaN, other cells such as strings are None
+func (s *Series) missing() interface{} {
+	if s.which == typeObj {
+		return nil
+	}
+	return math.NaN()
+}
+
+// nullMask returns a Series of bools that are true for missing values, or
+// for present values if notNull is true
+func (s *Series) nullMask(notNull bool) *Series {
+	newVals := make([]int, s.Len())
+	for i := range newVals {
+		if s.isNullAt(i) != notNull {
+			newVals[i] = 1
+		}
+	}
+	series := newSeriesFromInts(newVals, s.index, s.name)
+	series.dtype = "bool"
+	return series
+}
+
+// withValues returns a Series with the same index and name, holding vals
+func (s *Series) withValues(vals []interface{}) (*Series, error) {
+	builder := newTypedSliceBuilder(len(vals))
+	for _, val := range vals {
+		builder.push(val)
+	}
+	if err := builder.error(); err != nil {
+		return nil, err
+	}
+	res := builder.toSeries(s.index, s.name)
+	return &res, nil
+}
+
+// fillValue returns a copy of the Series with missing values replaced by
+// val. limit is the most values to fill, or 0 to fill every missing value
+func (s *Series) fillValue(val interface{}, limit int) (*Series, error) {
+	vals := s.values()
+	filled := 0
+	for i := range vals {
+		if limit > 0 && filled == limit {
+			break
+		}
+		if s.isNullAt(i) {
+			vals[i] = val
+			filled++
+		}
+	}
+	if filled == 0 {
+		return s.clone(), nil
+	}
+	return s.withValues(vals)
+}
+
+// fillMethod returns a copy of the Series with missing values replaced by
+// the previous present value, or the next one if backward is true. limit is
+// the most consecutive values to fill, or 0 to fill every missing value
+func (s *Series) fillMethod(backward bool, limit int) (*Series, error) {
+	vals := s.values()
+	filled := 0
+	var (
+		last  interface{}
+		have  bool
+		count int
+	)
+	for k := range vals {
+		i := k
+		if backward {
+			i = len(vals) - 1 - k
+		}
+		if !s.isNullAt(i) {
+			last, have, count = vals[i], true, 0
+			continue
+		}
+		if !have || (limit > 0 && count == limit) {
+			continue
+		}
+		vals[i] = last
+		count++
+		filled++
+	}
+	if filled == 0 {
+		return s.clone(), nil
+	}
+	return s.withValues(vals)
+}
+
+// interpolate returns a copy of the Series with missing values replaced by
+// linear interpolation between the present values around them. missing
+// values at the end get the last present value, missing values at the start
+// are kept. limit is the most consecutive values to fill, or 0 to fill every
+// missing value. returns false if the Series has values that aren't numbers
+func (s *Series) interpolate(limit int) (*Series, bool) {
+	if s.which == typeInt {
+		return s.clone(), true
+	}
+
+	nums := make([]float64, s.Len())
+	for i := range nums {
+		if s.isNullAt(i) {
+			nums[i] = math.NaN()
+			continue
+		}
+		switch x := s.At(i).(type) {
+		case int:
+			nums[i] = float64(x)
+		case float64:
+			nums[i] = x
+		default:
+			return nil, false
+		}
+	}
+
+	prev := -1
+	for i := 0; i <= len(nums); i++ {
+		if i < len(nums) && math.IsNaN(nums[i]) {
+			continue
+		}
+		// Fill the gap between the previous present value and this one
+		if prev != -1 {
+			for j := prev + 1; j < i; j++ {
+				if limit > 0 && j-prev > limit {
+					break
+				}
+				if i == len(nums) {
+					nums[j] = nums[prev]
+				} else {
+					nums[j] = nums[prev] + (nums[i]-nums[prev])*float64(j-prev)/float64(i-prev)
+				}
+			}
+		}
+		prev = i
+	}
+	return newSeriesFromFloats(nums, s.index, s.name), true
+}
+
+// dropNull returns a copy of the Series without missing values
+func (s *Series) dropNull() *Series {
+	pos := make([]int, 0, s.Len())
+	for i := 0; i < s.Len(); i++ {
+		if !s.isNullAt(i) {
+			pos = append(pos, i)
+		}
+	}
+	rows := axis{labels: s.index, size: s.Len()}
+	return s.take(pos, rows.take(pos))
+}
+
+// clone returns a copy of the Series
+func (s *Series) clone() *Series {
+	return s.take(allPositions(s.Len()), s.index)
+}
+
+// allPositions returns the positions 0 through n-1
+func allPositions(n int) []int {
+	pos := make([]int, n)
+	for i := range pos {
+		pos[i] = i
+	}
+	return pos
+}
+
+// toFillLimit converts the limit argument of fill methods, where None means
+// no limit
+func toFillLimit(v starlark.Value) (int, error) {
+	if v == nil || v == starlark.None {
+		return 0, nil
+	}
+	limit, ok := toIntMaybe(v)
+	if !ok || limit <= 0 {
+		return 0, fmt.Errorf("limit must be an int greater than 0")
+	}
+	return limit, nil
+}
+
+// toFillMethod converts the method argument of fillna, returning whether it
+// fills backward
+func toFillMethod(method string) (bool, error) {
+	switch method {
+	case "ffill", "pad":
+		return false, nil
+	case "bfill", "backfill":
+		return true, nil
+	}
+	return false, fmt.Errorf("invalid fill method %q, expected one of \"ffill\", \"pad\", \"bfill\" or \"backfill\"", method)
+}
+
+// unpackFillArgs unpacks the arguments of fillna. a nil fill value means
+// fill using the method
+func unpackFillArgs(b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (value starlark.Value, backward bool, limit int, err error) {
+	var methodVal, limitVal starlark.Value
+	if err = starlark.UnpackArgs(b.Name(), args, kwargs,
+		"value?", &value,
+		"method?", &methodVal,
+		"limit?", &limitVal,
+	); err != nil {
+		return nil, false, 0, err
+	}
+	if limit, err = toFillLimit(limitVal); err != nil {
+		return nil, false, 0, err
+	}
+	if value == starlark.None {
+		value = nil
+	}
+	method := toStrOrEmpty(methodVal)
+	if value != nil && method != "" {
+		return nil, false, 0, fmt.Errorf("cannot specify both a fill value and a method")
+	}
+	if value == nil && method == "" {
+		return nil, false, 0, fmt.Errorf("must specify a fill value or a method")
+	}
+	if method != "" {
+		if backward, err = toFillMethod(method); err != nil {
+			return nil, false, 0, err
+		}
+	}
+	return value, backward, limit, nil
+}
+
+// isna method returns a Series of booleans that are true for missing values
+func seriesIsNa(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 0); err != nil {
+		return nil, err
+	}
+	self := b.Receiver().(*Series)
+	return self.nullMask(false), nil
+}
+
+// notnull method returns a Series of booleans that are true for non-null values
+func seriesNotNull(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 0); err != nil {
+		return nil, err
+	}
+	self := b.Receiver().(*Series)
+	return self.nullMask(true), nil
+}
+
+// fillna method returns a copy of the Series with missing values filled by
+// a value or a method
+func seriesFillNa(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	self := b.Receiver().(*Series)
+	value, backward, limit, err := unpackFillArgs(b, args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return self.fillMethod(backward, limit)
+	}
+	val, ok := toScalarMaybe(value)
+	if !ok {
+		return nil, fmt.Errorf("fillna: value must be a scalar, got %s", value.Type())
+	}
+	return self.fillValue(val, limit)
+}
+
+// ffill and bfill methods return a copy of the Series with missing values
+// filled by the previous or next present value
+func seriesFillMethod(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var limitVal starlark.Value
+	self := b.Receiver().(*Series)
+
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs,
+		"limit?", &limitVal,
+	); err != nil {
+		return nil, err
+	}
+	limit, err := toFillLimit(limitVal)
+	if err != nil {
+		return nil, err
+	}
+	backward, err := toFillMethod(b.Name())
+	if err != nil {
+		return nil, err
+	}
+	return self.fillMethod(backward, limit)
+}
+
+// dropna method returns a copy of the Series without missing values
+func seriesDropNa(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 0); err != nil {
+		return nil, err
+	}
+	self := b.Receiver().(*Series)
+	return self.dropNull(), nil
+}
+
+// interpolate method returns a copy of the Series with missing values filled
+// by linear interpolation
+func seriesInterpolate(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var (
+		method   = "linear"
+		limitVal starlark.Value
+		self     = b.Receiver().(*Series)
+	)
+
+	if err := starlark.UnpackArgs("interpolate", args, kwargs,
+		"method?", &method,
+		"limit?", &limitVal,
+	); err != nil {
+		return nil, err
+	}
+	if method != "linear" {
+		return nil, fmt.Errorf("interpolate: only method \"linear\" is supported")
+	}
+	limit, err := toFillLimit(limitVal)
+	if err != nil {
+		return nil, err
+	}
+	res, ok := self.interpolate(limit)
+	if !ok {
+		return nil, fmt.Errorf("interpolate: Series must only contain numbers")
+	}
+	return res, nil
+}
+
+// mapColumns returns a DataFrame made by replacing each column with the
+// result of calling fn with it
+func (df *DataFrame) mapColumns(fn func(name string, s *Series) (*Series, error)) (*DataFrame, error) {
+	names := df.columnNames()
+	body := make([]Series, len(df.body))
+	for j := range df.body {
+		res, err := fn(names[j], &df.body[j])
+		if err != nil {
+			return nil, err
+		}
+		body[j] = *res
+	}
+	return newDataFrameConstructor(body, df.columns, df.index, df.outconf)
+}
+
+// isna method returns a DataFrame of booleans that are true for missing values
+func dataframeIsNa(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 0); err != nil {
+		return nil, err
+	}
+	self := b.Receiver().(*DataFrame)
+	return self.mapColumns(func(_ string, s *Series) (*Series, error) {
+		return s.nullMask(false), nil
+	})
+}
+
+// notna method returns a DataFrame of booleans that are true for non-null
+// values
+func dataframeNotNa(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 0); err != nil {
+		return nil, err
+	}
+	self := b.Receiver().(*DataFrame)
+	return self.mapColumns(func(_ string, s *Series) (*Series, error) {
+		return s.nullMask(true), nil
+	})
+}
+
+// fillna method returns a copy of the DataFrame with missing values filled
+// by a value, a dict of values for each column, or a method
+func dataframeFillNa(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	self := b.Receiver().(*DataFrame)
+	value, backward, limit, err := unpackFillArgs(b, args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return self.mapColumns(func(_ string, s *Series) (*Series, error) {
+			return s.fillMethod(backward, limit)
+		})
+	}
+
+	if dict, ok := value.(*starlark.Dict); ok {
+		fills := map[string]interface{}{}
+		for _, item := range dict.Items() {
+			val, ok := toScalarMaybe(item[1])
+			if !ok {
+				return nil, fmt.Errorf("fillna: value for column %q must be a scalar, got %s", toStr(item[0]), item[1].Type())
+			}
+			fills[toStr(item[0])] = val
+		}
+		return self.mapColumns(func(name string, s *Series) (*Series, error) {
+			if val, ok := fills[name]; ok {
+				return s.fillValue(val, limit)
+			}
+			return s.clone(), nil
+		})
+	}
+
+	val, ok := toScalarMaybe(value)
+	if !ok {
+		return nil, fmt.Errorf("fillna: value must be a scalar or dict, got %s", value.Type())
+	}
+	return self.mapColumns(func(_ string, s *Series) (*Series, error) {
+		return s.fillValue(val, limit)
+	})
+}
+
+// ffill and bfill methods return a copy of the DataFrame with missing values
+// filled by the previous or next present value in their column
+func dataframeFillMethod(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var limitVal starlark.Value
+	self := b.Receiver().(*DataFrame)
+
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs,
+		"limit?", &limitVal,
+	); err != nil {
+		return nil, err
+	}
+	limit, err := toFillLimit(limitVal)
+	if err != nil {
+		return nil, err
+	}
+	backward, err := toFillMethod(b.Name())
+	if err != nil {
+		return nil, err
+	}
+	return self.mapColumns(func(_ string, s *Series) (*Series, error) {
+		return s.fillMethod(backward, limit)
+	})
+}
+
+// interpolate method returns a copy of the DataFrame with missing values
+// filled by linear interpolation down each column. columns that contain
+// values other than numbers are left as they are
+func dataframeInterpolate(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var (
+		method   = "linear"
+		limitVal starlark.Value
+		self     = b.Receiver().(*DataFrame)
+	)
+
+	if err := starlark.UnpackArgs("interpolate", args, kwargs,
+		"method?", &method,
+		"limit?", &limitVal,
+	); err != nil {
+		return nil, err
+	}
+	if method != "linear" {
+		return nil, fmt.Errorf("interpolate: only method \"linear\" is supported")
+	}
+	limit, err := toFillLimit(limitVal)
+	if err != nil {
+		return nil, err
+	}
+	return self.mapColumns(func(_ string, s *Series) (*Series, error) {
+		if res, ok := s.interpolate(limit); ok {
+			return res, nil
+		}
+		return s.clone(), nil
+	})
+}
+
+// dropna method returns a copy of the DataFrame without the rows, or the
+// columns if axis is 1, that have missing values
+func dataframeDropNa(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var (
+		axisVal, howVal, threshVal, subsetVal starlark.Value
+		self                                  = b.Receiver().(*DataFrame)
+	)
+
+	if err := starlark.UnpackArgs("dropna", args, kwargs,
+		"axis?", &axisVal,
+		"how?", &howVal,
+		"thresh?", &threshVal,
+		"subset?", &subsetVal,
+	); err != nil {
+		return nil, err
+	}
+
+	dropColumns := false
+	if axisVal != nil {
+		switch toStr(axisVal) {
+		case "0", "index", "rows":
+		case "1", "columns":
+			dropColumns = true
+		default:
+			return nil, fmt.Errorf("dropna: invalid axis %s", axisVal)
+		}
+	}
+
+	how := toStrOrEmpty(howVal)
+	thresh := -1
+	if threshVal != nil && threshVal != starlark.None {
+		if how != "" {
+			return nil, fmt.Errorf("dropna: cannot set both how and thresh")
+		}
+		n, ok := toIntMaybe(threshVal)
+		if !ok {
+			return nil, fmt.Errorf("dropna: thresh must be an int")
+		}
+		thresh = n
+	}
+	switch how {
+	case "":
+		how = "any"
+	case "any", "all":
+	default:
+		return nil, fmt.Errorf("dropna: invalid how %q, expected \"any\" or \"all\"", how)
+	}
+
+	// rows and columns are the axes being checked for missing values, and
+	// the positions along the other axis to look at
+	rows := axis{labels: self.index, size: self.NumRows()}
+	cols := axis{labels: self.columns, size: self.NumCols()}
+	check, across := rows, cols
+	if dropColumns {
+		check, across = cols, rows
+	}
+
+	lookAt := allPositions(across.size)
+	if subset := toStrOrStrSliceOrNil(subsetVal); subset != nil {
+		lookAt = nil
+		for _, label := range subset {
+			found := false
+			for k := 0; k < across.size; k++ {
+				if across.labelAt(k) == label {
+					lookAt = append(lookAt, k)
+					found = true
+				}
+			}
+			if !found {
+				return nil, fmt.Errorf("dropna: subset label %q not found", label)
+			}
+		}
+	}
+
+	keep := make([]int, 0, check.size)
+	for k := 0; k < check.size; k++ {
+		present := 0
+		for _, p := range lookAt {
+			i, j := k, p
+			if dropColumns {
+				i, j = p, k
+			}
+			if !self.body[j].isNullAt(i) {
+				present++
+			}
+		}
+		switch {
+		case thresh >= 0:
+			if present >= thresh {
+				keep = append(keep, k)
+			}
+		case how == "all":
+			if present > 0 || len(lookAt) == 0 {
+				keep = append(keep, k)
+			}
+		default:
+			if present == len(lookAt) {
+				keep = append(keep, k)
+			}
+		}
+	}
+
+	if dropColumns {
+		body := make([]Series, len(keep))
+		for i, j := range keep {
+			body[i] = *self.body[j].clone()
+		}
+		return newDataFrameConstructor(body, cols.take(keep), self.index, self.outconf)
+	}
+	body := make([]Series, len(self.body))
+	for j := range self.body {
+		body[j] = *self.body[j].take(keep, nil)
+	}
+	return newDataFrameConstructor(body, self.columns, rows.take(keep), self.outconf)
+}
@@ -80,38 +80,11 @@ func (r *rowIter) RowSize() int {
 	return len(r.GetRow().data)
 }
 
-func (r *rowIter) MergeWith(q *rowIter, leftKey, rightKey, ignore int) *rowTuple {
-	leftElem := r.GetStr(leftKey)
-	rightElem := q.GetStr(rightKey)
-	if leftElem == rightElem {
-		mine := r.GetRow()
-		theirs := q.GetRow().removeFromStringList(ignore)
-		return &rowTuple{index: r.df.columns, data: mine.concat(theirs)}
-	}
-	return nil
-}
-
 type rowTuple struct {
 	index *Index
 	data  []interface{}
 }
 
-func (rt *rowTuple) removeFromStringList(i int) *rowTuple {
-	if i == -1 {
-		return rt
-	}
-	ls := rt.data
-	a := make([]interface{}, len(ls))
-	copy(a, ls)
-	copy(a[i:], a[i+1:])
-	a[len(a)-1] = nil
-	return &rowTuple{data: a[:len(a)-1], index: rt.index}
-}
-
-func (rt *rowTuple) concat(other *rowTuple) []interface{} {
-	return append(rt.data, other.data...)
-}
-
 func (rt *rowTuple) padToSize(num int) *rowTuple {
 	if len(rt.data) < num {
 		pad := make([]interface{}, num-len(rt.data))
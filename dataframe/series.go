@@ -152,7 +152,8 @@ func (s *Series) selectElemsWhereEqString(cmp string) (*Series, error) {
 	for k := 0; k < s.Len(); k++ {
 		elemVal := s.Index(k)
 		if elemVal == nil || elemVal == starlark.None {
-			builder.pushNil()
+			// missing values never equal the string
+			builder.push(false)
 			continue
 		}
 		if elemStr, ok := toStrMaybe(elemVal); ok {
@@ -563,7 +564,7 @@ func (s *Series) Binary(op syntax.Token, y starlark.Value, side starlark.Side) (
 		limit := max(s.Len(), other.Len())
 		for i := 0; i < limit; i++ {
 			if i >= s.Len() || i >= other.Len() {
-				builder.pushNil()
+				builder.push(math.NaN())
 				continue
 			}
 			if op == syntax.PLUS {
@@ -573,10 +574,11 @@ func (s *Series) Binary(op syntax.Token, y starlark.Value, side starlark.Side) (
 			}
 		}
 	} else if s.which == typeObj && other.which == typeObj {
+		builder.setType("object")
 		limit := max(s.Len(), other.Len())
 		for i := 0; i < limit; i++ {
 			if i >= s.Len() || i >= other.Len() {
-				builder.pushNil()
+				builder.push(nil)
 				continue
 			}
 			builder.push(fmt.Sprintf("%s%s", s.At(i), other.At(i)))
@@ -651,6 +653,16 @@ func seriesAttrSize(self *Series) (starlark.Value, error) {
 	return starlark.MakeInt(self.Len()), nil
 }
 
+// loc returns a LocIndexer which can retrieve or set elements by label
+func seriesAttrLoc(self *Series) (starlark.Value, error) {
+	return &LocIndexer{locator{series: self}}, nil
+}
+
+// iloc returns an ILocIndexer which can retrieve or set elements by position
+func seriesAttrILoc(self *Series) (starlark.Value, error) {
+	return &ILocIndexer{locator{series: self, positional: true}}, nil
+}
+
 func adaptToSeriesFromDataframe(methodName string) starlarkMethod {
 	return func(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 		self := b.Receiver().(*Series)
@@ -792,27 +804,6 @@ func seriesAsType(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple,
 	return series, nil
 }
 
-// notnull method returns a Series of booleans that are true for non-null values
-func seriesNotNull(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
-	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 0); err != nil {
-		return nil, err
-	}
-	self := b.Receiver().(*Series)
-
-	newVals := make([]int, 0, self.Len())
-	for _, val := range self.values() {
-		if val == nil {
-			newVals = append(newVals, 0)
-		} else {
-			newVals = append(newVals, 1)
-		}
-	}
-
-	series := newSeriesFromInts(newVals, self.index, self.name)
-	series.dtype = "bool"
-	return series, nil
-}
-
 // to_frame converts a Series to a DataFrame
 func seriesToFrame(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 0); err != nil {
@@ -919,7 +910,7 @@ func newSeries(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple
 		for k := 0; k < inData.Len(); k++ {
 			elemVal := inData.Index(k)
 			if elemVal == nil || elemVal == starlark.None {
-				builder.pushNil()
+				builder.push(nil)
 				continue
 			}
 			if scalar, ok := toScalarMaybe(elemVal); ok {
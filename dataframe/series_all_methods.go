@@ -19,13 +19,13 @@ var seriesAttributes = map[string]seriesAttrImpl{
 	"flags":                   attrNoImplSeries("flags"),
 	"hasnans":                 attrNoImplSeries("hasnans"),
 	"iat":                     attrNoImplSeries("iat"),
-	"iloc":                    attrNoImplSeries("iloc"),
+	"iloc":                    seriesAttrILoc,
 	"index":                   attrNoImplSeries("index"),
 	"is_monotonic":            attrNoImplSeries("is_monotonic"),
 	"is_monotonic_decreasing": attrNoImplSeries("is_monotonic_decreasing"),
 	"is_monotonic_increasing": attrNoImplSeries("is_monotonic_increasing"),
 	"is_unique":               attrNoImplSeries("is_unique"),
-	"loc":                     attrNoImplSeries("loc"),
+	"loc":                     seriesAttrLoc,
 	"name":                    seriesAttrName,
 	"nbytes":                  attrNoImplSeries("nbytes"),
 	"ndim":                    attrNoImplSeries("ndim"),
@@ -54,10 +54,10 @@ var seriesMethods = map[string]*starlark.Builtin{
 	"astype":            starlark.NewBuiltin("astype", seriesAsType),
 	"at_time":           starlark.NewBuiltin("at_time", methNoImplSeries("at_time")),
 	"autocorr":          starlark.NewBuiltin("autocorr", methNoImplSeries("autocorr")),
-	"backfill":          starlark.NewBuiltin("backfill", methNoImplSeries("backfill")),
+	"backfill":          starlark.NewBuiltin("backfill", seriesFillMethod),
 	"between":           starlark.NewBuiltin("between", methNoImplSeries("between")),
 	"between_time":      starlark.NewBuiltin("between_time", methNoImplSeries("between_time")),
-	"bfill":             starlark.NewBuiltin("bfill", methNoImplSeries("bfill")),
+	"bfill":             starlark.NewBuiltin("bfill", seriesFillMethod),
 	"bool":              starlark.NewBuiltin("bool", methNoImplSeries("bool")),
 	"cat":               starlark.NewBuiltin("cat", methNoImplSeries("cat")),
 	"clip":              starlark.NewBuiltin("clip", methNoImplSeries("clip")),
@@ -68,13 +68,13 @@ var seriesMethods = map[string]*starlark.Builtin{
 	"convert_dtypes":    starlark.NewBuiltin("convert_dtypes", methNoImplSeries("convert_dtypes")),
 	"copy":              starlark.NewBuiltin("copy", methNoImplSeries("copy")),
 	"corr":              starlark.NewBuiltin("corr", methNoImplSeries("corr")),
-	"count":             starlark.NewBuiltin("count", methNoImplSeries("count")),
+	"count":             starlark.NewBuiltin("count", seriesStat),
 	"cov":               starlark.NewBuiltin("cov", methNoImplSeries("cov")),
 	"cummax":            starlark.NewBuiltin("cummax", methNoImplSeries("cummax")),
 	"cummin":            starlark.NewBuiltin("cummin", methNoImplSeries("cummin")),
 	"cumprod":           starlark.NewBuiltin("cumprod", methNoImplSeries("cumprod")),
 	"cumsum":            starlark.NewBuiltin("cumsum", methNoImplSeries("cumsum")),
-	"describe":          starlark.NewBuiltin("describe", methNoImplSeries("describe")),
+	"describe":          starlark.NewBuiltin("describe", seriesDescribe),
 	"diff":              starlark.NewBuiltin("diff", methNoImplSeries("diff")),
 	"div":               starlark.NewBuiltin("div", methNoImplSeries("div")),
 	"divide":            starlark.NewBuiltin("divide", methNoImplSeries("divide")),
@@ -83,7 +83,7 @@ var seriesMethods = map[string]*starlark.Builtin{
 	"drop":              starlark.NewBuiltin("drop", methNoImplSeries("drop")),
 	"drop_duplicates":   starlark.NewBuiltin("drop_duplicates", methNoImplSeries("drop_duplicates")),
 	"droplevel":         starlark.NewBuiltin("droplevel", methNoImplSeries("droplevel")),
-	"dropna":            starlark.NewBuiltin("dropna", methNoImplSeries("dropna")),
+	"dropna":            starlark.NewBuiltin("dropna", seriesDropNa),
 	"dt":                starlark.NewBuiltin("dt", methNoImplSeries("dt")),
 	"duplicated":        starlark.NewBuiltin("duplicated", methNoImplSeries("duplicated")),
 	"eq":                starlark.NewBuiltin("eq", methNoImplSeries("eq")),
@@ -92,8 +92,8 @@ var seriesMethods = map[string]*starlark.Builtin{
 	"expanding":         starlark.NewBuiltin("expanding", methNoImplSeries("expanding")),
 	"explode":           starlark.NewBuiltin("explode", methNoImplSeries("explode")),
 	"factorize":         starlark.NewBuiltin("factorize", methNoImplSeries("factorize")),
-	"ffill":             starlark.NewBuiltin("ffill", methNoImplSeries("ffill")),
-	"fillna":            starlark.NewBuiltin("fillna", methNoImplSeries("fillna")),
+	"ffill":             starlark.NewBuiltin("ffill", seriesFillMethod),
+	"fillna":            starlark.NewBuiltin("fillna", seriesFillNa),
 	"filter":            starlark.NewBuiltin("filter", methNoImplSeries("filter")),
 	"first":             starlark.NewBuiltin("first", methNoImplSeries("first")),
 	"first_valid_index": starlark.NewBuiltin("first_valid_index", methNoImplSeries("first_valid_index")),
@@ -107,10 +107,10 @@ var seriesMethods = map[string]*starlark.Builtin{
 	"idxmax":            starlark.NewBuiltin("idxmax", methNoImplSeries("idxmax")),
 	"idxmin":            starlark.NewBuiltin("idxmin", methNoImplSeries("idxmin")),
 	"infer_objects":     starlark.NewBuiltin("infer_objects", methNoImplSeries("infer_objects")),
-	"interpolate":       starlark.NewBuiltin("interpolate", methNoImplSeries("interpolate")),
+	"interpolate":       starlark.NewBuiltin("interpolate", seriesInterpolate),
 	"isin":              starlark.NewBuiltin("isin", methNoImplSeries("isin")),
-	"isna":              starlark.NewBuiltin("isna", methNoImplSeries("isna")),
-	"isnull":            starlark.NewBuiltin("isnull", methNoImplSeries("isnull")),
+	"isna":              starlark.NewBuiltin("isna", seriesIsNa),
+	"isnull":            starlark.NewBuiltin("isnull", seriesIsNa),
 	"item":              starlark.NewBuiltin("item", methNoImplSeries("item")),
 	"items":             starlark.NewBuiltin("items", methNoImplSeries("items")),
 	"iteritems":         starlark.NewBuiltin("iteritems", methNoImplSeries("iteritems")),
@@ -124,11 +124,11 @@ var seriesMethods = map[string]*starlark.Builtin{
 	"mad":               starlark.NewBuiltin("mad", methNoImplSeries("mad")),
 	"map":               starlark.NewBuiltin("map", methNoImplSeries("map")),
 	"mask":              starlark.NewBuiltin("mask", methNoImplSeries("mask")),
-	"max":               starlark.NewBuiltin("max", methNoImplSeries("max")),
-	"mean":              starlark.NewBuiltin("mean", methNoImplSeries("mean")),
-	"median":            starlark.NewBuiltin("median", methNoImplSeries("median")),
+	"max":               starlark.NewBuiltin("max", seriesStat),
+	"mean":              starlark.NewBuiltin("mean", seriesStat),
+	"median":            starlark.NewBuiltin("median", seriesStat),
 	"memory_usage":      starlark.NewBuiltin("memory_usage", methNoImplSeries("memory_usage")),
-	"min":               starlark.NewBuiltin("min", methNoImplSeries("min")),
+	"min":               starlark.NewBuiltin("min", seriesStat),
 	"mod":               starlark.NewBuiltin("mod", methNoImplSeries("mod")),
 	"mode":              starlark.NewBuiltin("mode", methNoImplSeries("mode")),
 	"mul":               starlark.NewBuiltin("mul", methNoImplSeries("mul")),
@@ -136,11 +136,11 @@ var seriesMethods = map[string]*starlark.Builtin{
 	"ne":                starlark.NewBuiltin("ne", methNoImplSeries("ne")),
 	"nlargest":          starlark.NewBuiltin("nlargest", methNoImplSeries("nlargest")),
 	"notequals":         starlark.NewBuiltin("notequals", seriesNotEquals),
-	"notna":             starlark.NewBuiltin("notna", methNoImplSeries("notna")),
+	"notna":             starlark.NewBuiltin("notna", seriesNotNull),
 	"notnull":           starlark.NewBuiltin("notnull", seriesNotNull),
 	"nsmallest":         starlark.NewBuiltin("nsmallest", methNoImplSeries("nsmallest")),
 	"nunique":           starlark.NewBuiltin("nunique", methNoImplSeries("nunique")),
-	"pad":               starlark.NewBuiltin("pad", methNoImplSeries("pad")),
+	"pad":               starlark.NewBuiltin("pad", seriesFillMethod),
 	"pct_change":        starlark.NewBuiltin("pct_change", methNoImplSeries("pct_change")),
 	"pipe":              starlark.NewBuiltin("pipe", methNoImplSeries("pipe")),
 	"plot":              starlark.NewBuiltin("plot", methNoImplSeries("plot")),
@@ -148,7 +148,7 @@ var seriesMethods = map[string]*starlark.Builtin{
 	"pow":               starlark.NewBuiltin("pow", methNoImplSeries("pow")),
 	"prod":              starlark.NewBuiltin("prod", methNoImplSeries("prod")),
 	"product":           starlark.NewBuiltin("product", methNoImplSeries("product")),
-	"quantile":          starlark.NewBuiltin("quantile", methNoImplSeries("quantile")),
+	"quantile":          starlark.NewBuiltin("quantile", seriesQuantile),
 	"radd":              starlark.NewBuiltin("radd", methNoImplSeries("radd")),
 	"rank":              starlark.NewBuiltin("rank", methNoImplSeries("rank")),
 	"ravel":             starlark.NewBuiltin("ravel", methNoImplSeries("ravel")),
@@ -183,11 +183,11 @@ var seriesMethods = map[string]*starlark.Builtin{
 	"sort_values":       starlark.NewBuiltin("sort_values", methNoImplSeries("sort_values")),
 	"sparse":            starlark.NewBuiltin("sparse", methNoImplSeries("sparse")),
 	"squeeze":           starlark.NewBuiltin("squeeze", methNoImplSeries("squeeze")),
-	"std":               starlark.NewBuiltin("std", methNoImplSeries("std")),
+	"std":               starlark.NewBuiltin("std", seriesStat),
 	"str":               starlark.NewBuiltin("str", methNoImplSeries("str")),
 	"sub":               starlark.NewBuiltin("sub", methNoImplSeries("sub")),
 	"subtract":          starlark.NewBuiltin("subtract", methNoImplSeries("subtract")),
-	"sum":               starlark.NewBuiltin("sum", methNoImplSeries("sum")),
+	"sum":               starlark.NewBuiltin("sum", seriesStat),
 	"swapaxes":          starlark.NewBuiltin("swapaxes", methNoImplSeries("swapaxes")),
 	"swaplevel":         starlark.NewBuiltin("swaplevel", methNoImplSeries("swaplevel")),
 	"tail":              starlark.NewBuiltin("tail", methNoImplSeries("tail")),
@@ -221,7 +221,7 @@ var seriesMethods = map[string]*starlark.Builtin{
 	"unstack":           starlark.NewBuiltin("unstack", methNoImplSeries("unstack")),
 	"update":            starlark.NewBuiltin("update", methNoImplSeries("update")),
 	"value_counts":      starlark.NewBuiltin("value_counts", methNoImplSeries("value_counts")),
-	"var":               starlark.NewBuiltin("var", methNoImplSeries("var")),
+	"var":               starlark.NewBuiltin("var", seriesStat),
 	"view":              starlark.NewBuiltin("view", methNoImplSeries("view")),
 	"where":             starlark.NewBuiltin("where", methNoImplSeries("where")),
 	"xs":                starlark.NewBuiltin("xs", methNoImplSeries("xs")),
@@ -12,8 +12,13 @@ type SeriesGroupByResult struct {
 	lhsLabel string
 	rhsLabel string
 	grouping map[string]*Series
+	// value of the grouped column for each group
+	keyVals map[string]interface{}
 	// index of the source DataFrame
 	dfIndex *Index
+	// whether aggregations use the groups as their index, or as a column
+	asIndex bool
+	outconf *OutputConfig
 }
 
 // compile-time interface assertions
@@ -29,9 +34,20 @@ const (
 )
 
 var seriesGroupByResultMethods = map[string]*starlark.Builtin{
-	"count": starlark.NewBuiltin("count", seriesGroupByResultCount),
-	"sum":   starlark.NewBuiltin("sum", seriesGroupByResultSum),
-	"apply": starlark.NewBuiltin("apply", seriesGroupByResultApply),
+	"agg":     starlark.NewBuiltin("agg", seriesGroupByResultAgg),
+	"apply":   starlark.NewBuiltin("apply", seriesGroupByResultApply),
+	"count":   starlark.NewBuiltin("count", seriesGroupByResultReduce),
+	"first":   starlark.NewBuiltin("first", seriesGroupByResultReduce),
+	"last":    starlark.NewBuiltin("last", seriesGroupByResultReduce),
+	"max":     starlark.NewBuiltin("max", seriesGroupByResultReduce),
+	"mean":    starlark.NewBuiltin("mean", seriesGroupByResultReduce),
+	"median":  starlark.NewBuiltin("median", seriesGroupByResultReduce),
+	"min":     starlark.NewBuiltin("min", seriesGroupByResultReduce),
+	"nunique": starlark.NewBuiltin("nunique", seriesGroupByResultReduce),
+	"size":    starlark.NewBuiltin("size", seriesGroupByResultReduce),
+	"std":     starlark.NewBuiltin("std", seriesGroupByResultReduce),
+	"sum":     starlark.NewBuiltin("sum", seriesGroupByResultReduce),
+	"var":     starlark.NewBuiltin("var", seriesGroupByResultReduce),
 }
 
 // Freeze has no effect on the immutable SeriesGroupByResult
@@ -69,56 +85,94 @@ func (sgbr *SeriesGroupByResult) AttrNames() []string {
 	return builtinAttrNames(seriesGroupByResultMethods)
 }
 
-// sum method returns a Series that is the sum of each grouped result
-func seriesGroupByResultSum(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
-	if err := starlark.UnpackArgs("sum", args, kwargs); err != nil {
-		return nil, err
+// sortedKeys returns the groups in sorted order, along with the value of the
+// grouped column for each
+func (sgbr *SeriesGroupByResult) sortedKeys() ([]string, []interface{}) {
+	keys := getSortedKeys(sgbr.grouping)
+	keyVals := make([]interface{}, len(keys))
+	for i, k := range keys {
+		if val, ok := sgbr.keyVals[k]; ok {
+			keyVals[i] = val
+		} else {
+			keyVals[i] = k
+		}
 	}
-	self := b.Receiver().(*SeriesGroupByResult)
-
-	indexTexts := []string{}
-	vals := []int{}
+	return keys, keyVals
+}
 
-	sortedKeys := getSortedKeys(self.grouping)
-	for _, groupName := range sortedKeys {
-		series := self.grouping[groupName]
+// reduce methods return a Series that aggregates each grouped result, or a
+// DataFrame with a column for the groups if as_index is False
+func seriesGroupByResultReduce(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	self := b.Receiver().(*SeriesGroupByResult)
+	ddof, err := unpackAggArgs(b, args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	fn, err := lookupAggFunc(b.Name(), ddof)
+	if err != nil {
+		return nil, err
+	}
 
-		sum := 0
-		for i := 0; i < series.Len(); i++ {
-			elem := series.Index(i)
-			if num, err := starlark.AsInt32(elem); err == nil {
-				sum += num
-			}
+	keys, keyVals := self.sortedKeys()
+	col, err := aggregate(self.rhsLabel, fn, len(keys), func(i int) *Series {
+		return self.grouping[keys[i]]
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", b.Name(), err)
+	}
+	if !self.asIndex {
+		if b.Name() == "size" {
+			col.name = "size"
 		}
-
-		indexTexts = append(indexTexts, groupName)
-		vals = append(vals, sum)
+		return newAggDataFrame([]aggColumn{col}, keys, keyVals, self.lhsLabel, false, self.outconf)
 	}
-
-	index := NewTextIndex(indexTexts, self.lhsLabel)
-	return newSeriesFromInts(vals, index, self.rhsLabel), nil
+	return newAggSeries(col, keys, self.lhsLabel)
 }
 
-// sum method returns a Series that is the sum of each grouped result
-func seriesGroupByResultCount(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
-	if err := starlark.UnpackArgs("count", args, kwargs); err != nil {
+// agg method returns the result of applying aggregations to each grouped
+// result. a single aggregation returns a Series, a list or dict returns a
+// DataFrame with a column for each aggregation
+func seriesGroupByResultAgg(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var funcVal starlark.Value
+	self := b.Receiver().(*SeriesGroupByResult)
+
+	if err := starlark.UnpackArgs("agg", args, kwargs,
+		"func", &funcVal,
+	); err != nil {
 		return nil, err
 	}
-	self := b.Receiver().(*SeriesGroupByResult)
 
-	indexTexts := []string{}
-	vals := []int{}
+	var specs []aggSpec
+	if fns := toStrSliceOrNil(funcVal); fns != nil {
+		// A list of aggregations names each column after its aggregation
+		for _, fn := range fns {
+			specs = append(specs, aggSpec{column: self.rhsLabel, fn: fn, name: fn})
+		}
+	} else {
+		var err error
+		if specs, _, err = toAggSpecs(funcVal, []string{self.rhsLabel}); err != nil {
+			return nil, err
+		}
+	}
 
-	sortedKeys := getSortedKeys(self.grouping)
-	for _, groupName := range sortedKeys {
-		series := self.grouping[groupName]
-		count := series.Len()
-		indexTexts = append(indexTexts, groupName)
-		vals = append(vals, count)
+	keys, keyVals := self.sortedKeys()
+	cols := make([]aggColumn, len(specs))
+	for i, spec := range specs {
+		fn, err := lookupAggFunc(spec.fn, 1)
+		if err != nil {
+			return nil, err
+		}
+		if cols[i], err = aggregate(spec.name, fn, len(keys), func(i int) *Series {
+			return self.grouping[keys[i]]
+		}); err != nil {
+			return nil, fmt.Errorf("%s: %w", spec.fn, err)
+		}
 	}
 
-	index := NewTextIndex(indexTexts, self.lhsLabel)
-	return newSeriesFromInts(vals, index, self.rhsLabel), nil
+	if _, ok := toStrMaybe(funcVal); ok && self.asIndex {
+		return newAggSeries(cols[0], keys, self.lhsLabel)
+	}
+	return newAggDataFrame(cols, keys, keyVals, self.lhsLabel, self.asIndex, self.outconf)
 }
 
 // apply method returns a Series that is built by calling the given
@@ -12,6 +12,10 @@ func TestSeriesGet(t *testing.T) {
 	expectScriptOutput(t, "testdata/series_get.star", "testdata/series_get.expect.txt")
 }
 
+func TestSeriesLoc(t *testing.T) {
+	expectScriptOutput(t, "testdata/series_loc.star", "testdata/series_loc.expect.txt")
+}
+
 func TestSeriesPrint(t *testing.T) {
 	expectScriptOutput(t, "testdata/series_print.star", "testdata/series_print.expect.txt")
 }
@@ -52,6 +56,14 @@ func TestSeriesNotNull(t *testing.T) {
 	expectScriptOutput(t, "testdata/series_notnull.star", "testdata/series_notnull.expect.txt")
 }
 
+func TestSeriesMissing(t *testing.T) {
+	expectScriptOutput(t, "testdata/series_missing.star", "testdata/series_missing.expect.txt")
+}
+
+func TestSeriesStats(t *testing.T) {
+	expectScriptOutput(t, "testdata/series_stats.star", "testdata/series_stats.expect.txt")
+}
+
 func TestSeriesResetIndex(t *testing.T) {
 	expectScriptOutput(t, "testdata/series_reset_index.star",
 		"testdata/series_reset_index.expect.txt")
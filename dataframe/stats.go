@@ -0,0 +1,547 @@
+package dataframe
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"go.starlark.net/starlark"
+)
+
+// statOpts are the options shared by descriptive statistics
+type statOpts struct {
+	skipna bool
+	ddof   int
+}
+
+// statFunc computes a descriptive statistic of a Series
+type statFunc func(s *Series, opts statOpts) (interface{}, error)
+
+// statFuncs are the descriptive statistics of Series and DataFrames, by name
+var statFuncs = map[string]statFunc{
+	"count":  statCount,
+	"max":    func(s *Series, opts statOpts) (interface{}, error) { return s.extreme(1, opts.skipna) },
+	"mean":   floatStat(func(nums []float64, _ statOpts) float64 { return mean(nums) }),
+	"median": floatStat(func(nums []float64, _ statOpts) float64 { return median(nums) }),
+	"min":    func(s *Series, opts statOpts) (interface{}, error) { return s.extreme(-1, opts.skipna) },
+	"std":    floatStat(func(nums []float64, opts statOpts) float64 { return math.Sqrt(variance(nums, opts.ddof)) }),
+	"sum":    statSum,
+	"var":    floatStat(func(nums []float64, opts statOpts) float64 { return variance(nums, opts.ddof) }),
+}
+
+// isNumeric reports whether a Series holds numbers. Series of objects are
+// numeric if every present value is an int or float
+func (s *Series) isNumeric() bool {
+	switch s.which {
+	case typeInt:
+		return s.dtype != "datetime64[ns]" && s.dtype != "timedelta64[ns]"
+	case typeFloat:
+		return true
+	}
+	vals := nonNullValues(s)
+	if len(vals) == 0 {
+		return false
+	}
+	for _, v := range vals {
+		switch v.(type) {
+		case int, float64:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// floatValues returns the present values of a numeric Series as floats, and
+// whether any values are missing. ok is false if the Series isn't numeric
+func (s *Series) floatValues() (nums []float64, hasNull, ok bool) {
+	if !s.isNumeric() {
+		return nil, false, false
+	}
+	switch s.which {
+	case typeInt:
+		nums = make([]float64, len(s.valInts))
+		for i, n := range s.valInts {
+			nums[i] = float64(n)
+		}
+		return nums, false, true
+	case typeFloat:
+		nums = make([]float64, 0, len(s.valFloats))
+		for _, f := range s.valFloats {
+			if math.IsNaN(f) {
+				hasNull = true
+				continue
+			}
+			nums = append(nums, f)
+		}
+		return nums, hasNull, true
+	}
+	vals := nonNullValues(s)
+	nums, _, _ = numericValues(vals)
+	return nums, len(vals) != s.Len(), true
+}
+
+// floatStat makes a statFunc that computes a float from the present values
+// of a numeric Series
+func floatStat(fn func(nums []float64, opts statOpts) float64) statFunc {
+	return func(s *Series, opts statOpts) (interface{}, error) {
+		nums, hasNull, ok := s.floatValues()
+		if !ok {
+			return nil, errNotNumeric
+		}
+		if hasNull && !opts.skipna {
+			return math.NaN(), nil
+		}
+		return fn(nums, opts), nil
+	}
+}
+
+func statCount(s *Series, _ statOpts) (interface{}, error) {
+	count := 0
+	for i := 0; i < s.Len(); i++ {
+		if !s.isNullAt(i) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// statSum adds the values of a Series. ints and bools sum to an int, strings
+// are concatenated
+func statSum(s *Series, opts statOpts) (interface{}, error) {
+	switch s.which {
+	case typeInt:
+		if !s.isNumeric() {
+			return nil, errNotNumeric
+		}
+		sum := 0
+		for _, n := range s.valInts {
+			sum += n
+		}
+		return sum, nil
+	case typeFloat:
+		sum := 0.0
+		for _, f := range s.valFloats {
+			if math.IsNaN(f) {
+				if !opts.skipna {
+					return math.NaN(), nil
+				}
+				continue
+			}
+			sum += f
+		}
+		return sum, nil
+	}
+	if !opts.skipna && len(nonNullValues(s)) != s.Len() {
+		return math.NaN(), nil
+	}
+	return aggSum(s)
+}
+
+// extreme returns the smallest value if sign is -1, or the largest if sign
+// is 1, keeping the type of the values
+func (s *Series) extreme(sign int, skipna bool) (interface{}, error) {
+	switch s.which {
+	case typeInt:
+		if s.Len() == 0 {
+			return math.NaN(), nil
+		}
+		best := s.valInts[0]
+		for _, n := range s.valInts[1:] {
+			if (sign < 0 && n < best) || (sign > 0 && n > best) {
+				best = n
+			}
+		}
+		if s.dtype == "bool" {
+			return best != 0, nil
+		}
+		return best, nil
+	case typeFloat:
+		best := math.NaN()
+		for _, f := range s.valFloats {
+			if math.IsNaN(f) {
+				if !skipna {
+					return math.NaN(), nil
+				}
+				continue
+			}
+			if math.IsNaN(best) || (sign < 0 && f < best) || (sign > 0 && f > best) {
+				best = f
+			}
+		}
+		return best, nil
+	}
+	vals := nonNullValues(s)
+	if !skipna && len(vals) != s.Len() {
+		return math.NaN(), nil
+	}
+	return extreme(vals, sign)
+}
+
+// quantile returns the q-th quantile of sorted numbers, interpolating
+// linearly between the values around it
+func quantile(sorted []float64, q float64) float64 {
+	if len(sorted) == 0 {
+		return math.NaN()
+	}
+	pos := q * float64(len(sorted)-1)
+	lo := int(math.Floor(pos))
+	hi := int(math.Ceil(pos))
+	return sorted[lo] + (sorted[hi]-sorted[lo])*(pos-float64(lo))
+}
+
+// sortedFloats returns the present values of a numeric Series in order
+func (s *Series) sortedFloats() ([]float64, bool) {
+	nums, _, ok := s.floatValues()
+	if !ok {
+		return nil, false
+	}
+	sort.Float64s(nums)
+	return nums, true
+}
+
+// toQuantiles converts the q argument of quantile methods, reporting whether
+// it was a list
+func toQuantiles(v starlark.Value) ([]float64, bool, error) {
+	if v == nil {
+		return []float64{0.5}, false, nil
+	}
+	if f, ok := starlark.AsFloat(v); ok {
+		if f < 0 || f > 1 {
+			return nil, false, fmt.Errorf("quantile: q must be between 0 and 1")
+		}
+		return []float64{f}, false, nil
+	}
+	items, ok := v.(starlark.Indexable)
+	if !ok {
+		return nil, false, fmt.Errorf("quantile: q must be a float or a list of floats")
+	}
+	qs := make([]float64, items.Len())
+	for i := range qs {
+		f, ok := starlark.AsFloat(items.Index(i))
+		if !ok || f < 0 || f > 1 {
+			return nil, false, fmt.Errorf("quantile: q must be between 0 and 1")
+		}
+		qs[i] = f
+	}
+	return qs, true, nil
+}
+
+// quantileIndex returns an Index labelled by quantiles
+func quantileIndex(qs []float64) *Index {
+	vals := make([]interface{}, len(qs))
+	for i, q := range qs {
+		vals[i] = q
+	}
+	return NewObjIndex(vals, "")
+}
+
+// percentileLabel formats a percentile the way describe labels it, like "25%"
+func percentileLabel(p float64) string {
+	return strconv.FormatFloat(p*100, 'f', -1, 64) + "%"
+}
+
+// describeNumeric summarizes a numeric Series
+func (s *Series) describeNumeric(percentiles []float64) ([]string, []interface{}) {
+	nums, _ := s.sortedFloats()
+	labels := []string{"count", "mean", "std", "min"}
+	vals := []interface{}{float64(len(nums)), mean(nums), math.Sqrt(variance(nums, 1)), math.NaN()}
+	if len(nums) > 0 {
+		vals[3] = nums[0]
+	}
+	for _, p := range percentiles {
+		labels = append(labels, percentileLabel(p))
+		vals = append(vals, quantile(nums, p))
+	}
+	labels = append(labels, "max")
+	if len(nums) > 0 {
+		vals = append(vals, nums[len(nums)-1])
+	} else {
+		vals = append(vals, math.NaN())
+	}
+	return labels, vals
+}
+
+// describeObjects summarizes a Series that isn't numeric, counting its
+// values and finding the most frequent one
+func (s *Series) describeObjects() ([]string, []interface{}) {
+	vals := nonNullValues(s)
+	counts := map[interface{}]int{}
+	var top interface{}
+	for _, v := range vals {
+		counts[v]++
+		if top == nil || counts[v] > counts[top] {
+			top = v
+		}
+	}
+	labels := []string{"count", "unique", "top", "freq"}
+	if top == nil {
+		return labels, []interface{}{len(vals), 0, nil, nil}
+	}
+	return labels, []interface{}{len(vals), len(counts), top, counts[top]}
+}
+
+// toPercentiles converts the percentiles argument of describe, which always
+// includes the median
+func toPercentiles(v starlark.Value) ([]float64, error) {
+	if v == nil || v == starlark.None {
+		return []float64{0.25, 0.5, 0.75}, nil
+	}
+	ps, isList, err := toQuantiles(v)
+	if err != nil || !isList {
+		return nil, fmt.Errorf("describe: percentiles must be a list of floats between 0 and 1")
+	}
+	hasMedian := false
+	for _, p := range ps {
+		hasMedian = hasMedian || p == 0.5
+	}
+	if !hasMedian {
+		ps = append(ps, 0.5)
+	}
+	sort.Float64s(ps)
+	return ps, nil
+}
+
+// unpackStatArgs unpacks the arguments of descriptive statistics. std and
+// var accept ddof
+func unpackStatArgs(b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (statOpts, bool, error) {
+	opts := statOpts{skipna: true, ddof: 1}
+	numericOnly := false
+	params := []interface{}{"skipna?", &opts.skipna, "numeric_only?", &numericOnly}
+	if b.Name() == "std" || b.Name() == "var" {
+		params = append(params, "ddof?", &opts.ddof)
+	}
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, params...); err != nil {
+		return opts, false, err
+	}
+	return opts, numericOnly, nil
+}
+
+// stat methods return a descriptive statistic of the Series, such as its mean
+func seriesStat(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	self := b.Receiver().(*Series)
+	opts, numericOnly, err := unpackStatArgs(b, args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	if numericOnly && !self.isNumeric() {
+		return nil, fmt.Errorf("%s: Series is not numeric", b.Name())
+	}
+	val, err := statFuncs[b.Name()](self, opts)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", b.Name(), err)
+	}
+	return convertToStarlark(val)
+}
+
+// quantile method returns the value at the given quantile of the Series, or a
+// Series of values if given a list of quantiles
+func seriesQuantile(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var (
+		qVal starlark.Value
+		self = b.Receiver().(*Series)
+	)
+
+	if err := starlark.UnpackArgs("quantile", args, kwargs,
+		"q?", &qVal,
+	); err != nil {
+		return nil, err
+	}
+	qs, isList, err := toQuantiles(qVal)
+	if err != nil {
+		return nil, err
+	}
+	nums, ok := self.sortedFloats()
+	if !ok {
+		return nil, fmt.Errorf("quantile: %w", errNotNumeric)
+	}
+
+	vals := make([]float64, len(qs))
+	for i, q := range qs {
+		vals[i] = quantile(nums, q)
+	}
+	if !isList {
+		return starlark.Float(vals[0]), nil
+	}
+	return newSeriesFromFloats(vals, quantileIndex(qs), self.name), nil
+}
+
+// describe method returns a Series that summarizes the Series. numeric Series
+// get their count, mean, std, min, percentiles and max, other Series get
+// their count, number of unique values, most frequent value and its frequency
+func seriesDescribe(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var (
+		percentilesVal starlark.Value
+		self           = b.Receiver().(*Series)
+	)
+
+	if err := starlark.UnpackArgs("describe", args, kwargs,
+		"percentiles?", &percentilesVal,
+	); err != nil {
+		return nil, err
+	}
+	percentiles, err := toPercentiles(percentilesVal)
+	if err != nil {
+		return nil, err
+	}
+
+	labels, vals := self.describeObjects()
+	if self.isNumeric() && self.dtype != "bool" {
+		labels, vals = self.describeNumeric(percentiles)
+	}
+	builder := newTypedSliceBuilder(len(vals))
+	for _, val := range vals {
+		builder.push(val)
+	}
+	if err := builder.error(); err != nil {
+		return nil, err
+	}
+	res := builder.toSeries(NewTextIndex(labels, ""), self.name)
+	return &res, nil
+}
+
+// stat methods return a Series of a descriptive statistic, such as the mean,
+// of each column of the DataFrame
+func dataframeStat(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	self := b.Receiver().(*DataFrame)
+	opts, numericOnly, err := unpackStatArgs(b, args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	builder := newTypedSliceBuilder(self.NumCols())
+	for j, name := range self.columnNames() {
+		col := &self.body[j]
+		if numericOnly && !col.isNumeric() {
+			continue
+		}
+		val, err := statFuncs[b.Name()](col, opts)
+		if err == errNotNumeric {
+			return nil, fmt.Errorf("%s: column %q is not numeric, use numeric_only=True to skip it", b.Name(), name)
+		} else if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+		builder.push(val)
+	}
+	if err := builder.error(); err != nil {
+		return nil, err
+	}
+	res := builder.toSeries(NewTextIndex(names, ""), "")
+	return &res, nil
+}
+
+// quantile method returns a Series of the value at the given quantile of each
+// column, or a DataFrame with a row for each quantile if given a list
+func dataframeQuantile(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var (
+		qVal        starlark.Value
+		numericOnly bool
+		self        = b.Receiver().(*DataFrame)
+	)
+
+	if err := starlark.UnpackArgs("quantile", args, kwargs,
+		"q?", &qVal,
+		"numeric_only?", &numericOnly,
+	); err != nil {
+		return nil, err
+	}
+	qs, isList, err := toQuantiles(qVal)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		names []string
+		body  []Series
+	)
+	for j, name := range self.columnNames() {
+		col := &self.body[j]
+		nums, ok := col.sortedFloats()
+		if !ok {
+			if numericOnly {
+				continue
+			}
+			return nil, fmt.Errorf("quantile: column %q is not numeric, use numeric_only=True to skip it", name)
+		}
+		vals := make([]float64, len(qs))
+		for i, q := range qs {
+			vals[i] = quantile(nums, q)
+		}
+		names = append(names, name)
+		body = append(body, *newSeriesFromFloats(vals, nil, ""))
+	}
+
+	if !isList {
+		vals := make([]float64, len(body))
+		for j := range body {
+			vals[j] = body[j].valFloats[0]
+		}
+		return newSeriesFromFloats(vals, NewTextIndex(names, ""), strconv.FormatFloat(qs[0], 'f', -1, 64)), nil
+	}
+	return newDataFrameConstructor(body, NewTextIndex(names, ""), quantileIndex(qs), self.outconf)
+}
+
+// describe method returns a DataFrame that summarizes each column. if the
+// DataFrame has numeric columns only those are summarized
+func dataframeDescribe(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var (
+		percentilesVal starlark.Value
+		self           = b.Receiver().(*DataFrame)
+	)
+
+	if err := starlark.UnpackArgs("describe", args, kwargs,
+		"percentiles?", &percentilesVal,
+	); err != nil {
+		return nil, err
+	}
+	percentiles, err := toPercentiles(percentilesVal)
+	if err != nil {
+		return nil, err
+	}
+
+	numeric := false
+	for j := range self.body {
+		if self.body[j].isNumeric() && self.body[j].dtype != "bool" {
+			numeric = true
+			break
+		}
+	}
+
+	var (
+		names  []string
+		labels []string
+		cols   [][]interface{}
+	)
+	for j, name := range self.columnNames() {
+		col := &self.body[j]
+		isNumeric := col.isNumeric() && col.dtype != "bool"
+		if numeric && !isNumeric {
+			continue
+		}
+		var vals []interface{}
+		if numeric {
+			labels, vals = col.describeNumeric(percentiles)
+		} else {
+			labels, vals = col.describeObjects()
+		}
+		names = append(names, name)
+		cols = append(cols, vals)
+	}
+
+	builder := newTableBuilder(len(cols), len(labels))
+	for i := range labels {
+		row := make([]interface{}, len(cols))
+		for j := range cols {
+			row[j] = cols[j][i]
+		}
+		builder.pushRow(row)
+	}
+	body, err := builder.body()
+	if err != nil {
+		return nil, err
+	}
+	return newDataFrameConstructor(body, NewTextIndex(names, ""), NewTextIndex(labels, ""), self.outconf)
+}
@@ -240,16 +240,6 @@ func (t *typedSliceBuilder) push(val interface{}) {
 	}
 }
 
-func (t *typedSliceBuilder) pushNil() {
-	if t.whichVals == typeInt {
-		t.valInts = append(t.valInts, 0)
-	} else if t.whichVals == typeFloat {
-		t.valFloats = append(t.valFloats, 0.0)
-	} else if t.whichVals == typeObj {
-		t.valObjs = append(t.valObjs, nil)
-	}
-}
-
 func (t *typedSliceBuilder) pushKeyVal(key string, val interface{}) {
 	t.keyList = append(t.keyList, key)
 	t.push(val)
@@ -0,0 +1,299 @@
+package csv
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	startime "go.starlark.net/lib/time"
+	"go.starlark.net/starlark"
+)
+
+// converter converts a cell string to a starlark value
+type converter func(thread *starlark.Thread, cell string) (starlark.Value, error)
+
+// converterTypes are the type names accepted as converters
+var converterTypes = map[string]cellType{
+	"str":   typeString,
+	"int":   typeInt,
+	"float": typeFloat,
+	"bool":  typeBool,
+	"date":  typeDate,
+}
+
+// initConverters parses the converters argument. keys are column indexes, or
+// field names when reading dicts
+func (o *readOpts) initConverters(byName bool) error {
+	if o.converters == nil {
+		return nil
+	}
+	for _, item := range o.converters.Items() {
+		conv, err := o.newConverter(item[1])
+		if err != nil {
+			return fmt.Errorf("converters[%s]: %s", item[0], err)
+		}
+		switch k := item[0].(type) {
+		case starlark.Int:
+			i, ok := k.Int64()
+			if !ok || i < 0 {
+				return fmt.Errorf("converters: invalid column index %s", k)
+			}
+			if o.indexConverters == nil {
+				o.indexConverters = map[int]converter{}
+			}
+			o.indexConverters[int(i)] = conv
+		case starlark.String:
+			if !byName {
+				return fmt.Errorf("converters: column names require read_dicts or dict_reader, use column indexes. got: %s", k)
+			}
+			if o.nameConverters == nil {
+				o.nameConverters = map[string]converter{}
+			}
+			o.nameConverters[string(k)] = conv
+		default:
+			return fmt.Errorf("converters: expected keys to be column names or indexes. got: '%s'", k.Type())
+		}
+	}
+	return nil
+}
+
+// newConverter creates a converter from a function or type name. type names
+// other than "str" convert null values to None
+func (o *readOpts) newConverter(v starlark.Value) (converter, error) {
+	switch x := v.(type) {
+	case starlark.String:
+		t, ok := converterTypes[string(x)]
+		if !ok {
+			return nil, fmt.Errorf("unknown type %s. expected one of \"str\", \"int\", \"float\", \"bool\", \"date\" or a function", x)
+		}
+		return func(_ *starlark.Thread, cell string) (starlark.Value, error) {
+			if t != typeString && o.nulls[cell] {
+				return starlark.None, nil
+			}
+			return parseCell(cell, t)
+		}, nil
+	case starlark.Callable:
+		return func(thread *starlark.Thread, cell string) (starlark.Value, error) {
+			return starlark.Call(thread, x, starlark.Tuple{starlark.String(cell)}, nil)
+		}, nil
+	}
+	return nil, fmt.Errorf("expected a type name or function. got: '%s'", v.Type())
+}
+
+// convertRecord converts each cell of a record. row is the 1-based row number
+// of the record, names are field names if reading dicts. types are inferred
+// column types, cells of columns past the end of types are inferred separately
+func (o *readOpts) convertRecord(thread *starlark.Thread, record []string, row int, names []string, types []cellType) ([]starlark.Value, error) {
+	vals := make([]starlark.Value, len(record))
+	for j, cell := range record {
+		var name string
+		if j < len(names) {
+			name = names[j]
+		}
+
+		conv, ok := o.indexConverters[j]
+		if !ok && name != "" {
+			conv, ok = o.nameConverters[name]
+		}
+		switch {
+		case ok:
+			v, err := conv(thread, cell)
+			if err != nil {
+				return nil, cellError(row, j, name, err)
+			}
+			vals[j] = v
+		case o.inferTypes:
+			if o.nulls[cell] {
+				vals[j] = starlark.None
+				continue
+			}
+			// columns without a type have only had null cells so far
+			t := inferCell(cell)
+			if j < len(types) && types[j] != typeNull {
+				t = types[j]
+			}
+			v, err := parseCell(cell, t)
+			if err != nil {
+				return nil, cellError(row, j, name, err)
+			}
+			vals[j] = v
+		default:
+			vals[j] = starlark.String(cell)
+		}
+	}
+	return vals, nil
+}
+
+func cellError(row, col int, name string, err error) error {
+	if name != "" {
+		return fmt.Errorf("row %d, column %d (%q): %s", row, col+1, name, err)
+	}
+	return fmt.Errorf("row %d, column %d: %s", row, col+1, err)
+}
+
+// cellType is the type of a csv cell
+type cellType int
+
+const (
+	// typeNull is the type of empty cells, which don't affect inferred column
+	// types
+	typeNull cellType = iota
+	typeBool
+	typeInt
+	typeFloat
+	typeDate
+	typeString
+)
+
+// dateLayouts are the ISO 8601 formats recognized as dates
+var dateLayouts = []string{
+	"2006-01-02",
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+}
+
+// nullValues is the set of cells that represent missing values
+type nullValues map[string]bool
+
+// defaultNullValues treats empty cells as missing
+var defaultNullValues = nullValues{"": true}
+
+// initNulls parses the na_values argument. na_values add to the default null
+// values unless keep_default_na is false
+func (o *readOpts) initNulls() error {
+	o.nulls = nullValues{}
+	if o.keepDefaultNA {
+		for s := range defaultNullValues {
+			o.nulls[s] = true
+		}
+	}
+	if o.naValues == nil || o.naValues == starlark.None {
+		return nil
+	}
+	strs, err := stringList("na_values", o.naValues)
+	if err != nil {
+		return err
+	}
+	for _, s := range strs {
+		o.nulls[s] = true
+	}
+	return nil
+}
+
+// infer returns the narrowest type of a cell, which is typeNull for null
+// values
+func (n nullValues) infer(cell string) cellType {
+	if n[cell] {
+		return typeNull
+	}
+	return inferCell(cell)
+}
+
+// inferCell returns the narrowest type of a non-null cell
+func inferCell(cell string) cellType {
+	if strings.EqualFold(cell, "true") || strings.EqualFold(cell, "false") {
+		return typeBool
+	}
+	if leadingZero(cell) {
+		// identifiers like zip codes lose meaning when parsed as numbers
+		return typeString
+	}
+	if _, err := strconv.ParseInt(cell, 10, 64); err == nil {
+		return typeInt
+	}
+	// ParseFloat accepts words like "inf" & "nan", which should stay strings
+	if _, err := strconv.ParseFloat(cell, 64); err == nil && strings.ContainsAny(cell, "0123456789") {
+		return typeFloat
+	}
+	if _, err := parseDate(cell); err == nil {
+		return typeDate
+	}
+	return typeString
+}
+
+// leadingZero reports whether a numeric-looking cell has a leading zero, such
+// as "007"
+func leadingZero(cell string) bool {
+	cell = strings.TrimLeft(cell, "+-")
+	return len(cell) > 1 && cell[0] == '0' && cell[1] >= '0' && cell[1] <= '9'
+}
+
+// mergeTypes returns the type that fits values of both types
+func mergeTypes(a, b cellType) cellType {
+	switch {
+	case a == b || b == typeNull:
+		return a
+	case a == typeNull:
+		return b
+	case (a == typeInt && b == typeFloat) || (a == typeFloat && b == typeInt):
+		return typeFloat
+	}
+	return typeString
+}
+
+// inferColumns infers the type of each column from records
+func (n nullValues) inferColumns(records [][]string) []cellType {
+	var types []cellType
+	for _, record := range records {
+		types = n.widen(types, record)
+	}
+	return types
+}
+
+// widen merges the types of a record's cells into column types, adding
+// columns past the end of types
+func (n nullValues) widen(types []cellType, record []string) []cellType {
+	for j, cell := range record {
+		if j == len(types) {
+			types = append(types, typeNull)
+		}
+		types[j] = mergeTypes(types[j], n.infer(cell))
+	}
+	return types
+}
+
+// parseCell converts a cell to a starlark value of type t
+func parseCell(cell string, t cellType) (starlark.Value, error) {
+	switch t {
+	case typeNull:
+		return starlark.None, nil
+	case typeBool:
+		b, err := strconv.ParseBool(strings.ToLower(cell))
+		if err != nil {
+			return nil, fmt.Errorf("cannot convert %q to bool", cell)
+		}
+		return starlark.Bool(b), nil
+	case typeInt:
+		i, err := strconv.ParseInt(cell, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("cannot convert %q to int", cell)
+		}
+		return starlark.MakeInt64(i), nil
+	case typeFloat:
+		f, err := strconv.ParseFloat(cell, 64)
+		if err != nil {
+			return nil, fmt.Errorf("cannot convert %q to float", cell)
+		}
+		return starlark.Float(f), nil
+	case typeDate:
+		t, err := parseDate(cell)
+		if err != nil {
+			return nil, fmt.Errorf("cannot convert %q to date", cell)
+		}
+		return startime.Time(t), nil
+	}
+	return starlark.String(cell), nil
+}
+
+func parseDate(cell string) (time.Time, error) {
+	var err error
+	for _, layout := range dateLayouts {
+		var t time.Time
+		if t, err = time.Parse(layout, cell); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
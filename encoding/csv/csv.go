@@ -1,14 +1,13 @@
 package csv
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/csv"
 	"fmt"
-	"io"
 	"strings"
 	"sync"
 
-	"github.com/qri-io/starlib/util"
 	"github.com/qri-io/starlib/util/replacecr"
 	"go.starlark.net/starlark"
 	"go.starlark.net/starlarkstruct"
@@ -33,6 +32,14 @@ func LoadModule() (starlark.StringDict, error) {
 				Members: starlark.StringDict{
 					"read_all":  starlark.NewBuiltin("read_all", ReadAll),
 					"write_all": starlark.NewBuiltin("write_all", WriteAll),
+					"reader":    starlark.NewBuiltin("reader", NewReader),
+					"writer":    starlark.NewBuiltin("writer", NewWriter),
+
+					"read_dicts":  starlark.NewBuiltin("read_dicts", ReadDicts),
+					"dict_reader": starlark.NewBuiltin("dict_reader", NewDictReader),
+					"write_dicts": starlark.NewBuiltin("write_dicts", WriteDicts),
+
+					"sniff": starlark.NewBuiltin("sniff", SniffFunc),
 				},
 			},
 		}
@@ -40,80 +47,125 @@ func LoadModule() (starlark.StringDict, error) {
 	return csvModule, nil
 }
 
-// ReadAll gets all values from a csv source
-func ReadAll(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
-	var (
-		r io.Reader
+// readOpts are the arguments shared by functions that read csv data
+type readOpts struct {
+	source                       starlark.Value
+	comma, comment               starlark.String
+	lazyQuotes, trimLeadingSpace starlark.Bool
+	fieldsPerRecord, skip        int
+	inferTypes                   bool
+	naValues                     starlark.Value
+	keepDefaultNA                bool
+	converters                   *starlark.Dict
+	encoding                     string
+
+	// converters parsed by initConverters, keyed by column index or name
+	indexConverters map[int]converter
+	nameConverters  map[string]converter
+	// nulls are the cells read as None, parsed by initNulls
+	nulls nullValues
+}
 
-		source                       starlark.Value
-		lazyQuotes, trimLeadingSpace starlark.Bool
-		skip                         = starlark.MakeInt(0)
-		fieldsPerRecord              = starlark.MakeInt(0)
-		_comma, _comment             starlark.String
-	)
-	err := starlark.UnpackArgs("read_all", args, kwargs,
-		"source", &source,
-		"comma?", &_comma,
-		"comment", &_comment,
-		"lazy_quotes", &lazyQuotes,
-		"trim_leading_space", &trimLeadingSpace,
-		"fields_per_record", &fieldsPerRecord,
-		"skip", &skip)
+// params returns name, value pairs for starlark.UnpackArgs
+func (o *readOpts) params() []interface{} {
+	return []interface{}{
+		"source", &o.source,
+		"comma?", &o.comma,
+		"comment?", &o.comment,
+		"lazy_quotes?", &o.lazyQuotes,
+		"trim_leading_space?", &o.trimLeadingSpace,
+		"fields_per_record?", &o.fieldsPerRecord,
+		"skip?", &o.skip,
+		"infer_types?", &o.inferTypes,
+		"na_values?", &o.naValues,
+		"converters?", &o.converters,
+		"encoding?", &o.encoding,
+		"keep_default_na?", &o.keepDefaultNA,
+	}
+}
 
+// reader creates a csv reader for the source, skipping any leading rows
+func (o *readOpts) reader() (*csv.Reader, error) {
+	src, err := sourceReader(o.source, o.encoding)
 	if err != nil {
 		return nil, err
 	}
-
-	switch source.Type() {
-	case "string":
-		str := string(source.(starlark.String))
-		r = strings.NewReader(str)
+	r := bufio.NewReaderSize(src, sniffSampleSize+1)
+
+	var comma rune
+	if o.comma == "auto" {
+		// peeking returns an error when sources are shorter than the sample size,
+		// sniffing uses whatever is available
+		peeked, _ := r.Peek(sniffSampleSize + 1)
+		comma = Sniff(sniffSample(string(peeked)), sniffDelimiters).Delimiter
+	} else if comma, err = delimiter(o.comma); err != nil {
+		return nil, err
 	}
-	csvr := csv.NewReader(replacecr.Reader(r))
-	csvr.LazyQuotes = bool(lazyQuotes)
-	csvr.TrimLeadingSpace = bool(trimLeadingSpace)
 
-	comma := string(_comma)
-	if comma == "" {
-		comma = ","
-	} else if len(comma) != 1 {
-		return starlark.None, fmt.Errorf("expected comma param to be a single-character string")
-	}
-	csvr.Comma = []rune(comma)[0]
+	csvr := csv.NewReader(replacecr.Reader(r))
+	csvr.LazyQuotes = bool(o.lazyQuotes)
+	csvr.TrimLeadingSpace = bool(o.trimLeadingSpace)
+	csvr.Comma = comma
 
-	comment := string(_comment)
+	comment := string(o.comment)
 	if comment != "" && len(comment) != 1 {
-		return starlark.None, fmt.Errorf("expected comment param to be a single-character string")
+		return nil, fmt.Errorf("expected comment param to be a single-character string")
 	} else if comment != "" {
 		csvr.Comment = []rune(comment)[0]
 	}
 
-	if fpr, ok := fieldsPerRecord.Int64(); ok && fpr != 0 {
-		csvr.FieldsPerRecord = int(fpr)
+	if o.fieldsPerRecord != 0 {
+		csvr.FieldsPerRecord = o.fieldsPerRecord
 	}
 
-	if s, ok := skip.Int64(); ok {
-		for i := 0; i < int(s); i++ {
-			if _, err := csvr.Read(); err != nil {
-				return starlark.None, err
-			}
+	for i := 0; i < o.skip; i++ {
+		if _, err := csvr.Read(); err != nil {
+			return nil, err
 		}
 	}
+	return csvr, nil
+}
+
+// sniffSample returns the leading lines of a source used to detect its
+// delimiter
+func sniffSample(source string) string {
+	if len(source) <= sniffSampleSize {
+		return source
+	}
+	sample := source[:sniffSampleSize]
+	if i := strings.LastIndexAny(sample, "\r\n"); i > 0 {
+		sample = sample[:i]
+	}
+	return sample
+}
 
-	strs, err := csvr.ReadAll()
+// delimiter parses a comma argument, defaulting to ","
+func delimiter(s starlark.String) (rune, error) {
+	comma := string(s)
+	if comma == "" {
+		comma = ","
+	} else if len(comma) != 1 {
+		return 0, fmt.Errorf("expected comma param to be a single-character string")
+	}
+	return []rune(comma)[0], nil
+}
+
+// ReadAll gets all values from a csv source
+func ReadAll(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	opts := &readOpts{keepDefaultNA: true}
+	if err := starlark.UnpackArgs("read_all", args, kwargs, opts.params()...); err != nil {
+		return nil, err
+	}
+
+	r, err := opts.newReader(thread, nil)
 	if err != nil {
 		return starlark.None, err
 	}
-
-	vals := make([]starlark.Value, len(strs))
-	for i, rowStr := range strs {
-		row := make([]starlark.Value, len(rowStr))
-		for j, cell := range rowStr {
-			row[j] = starlark.String(cell)
-		}
-		vals[i] = starlark.NewList(row)
+	rows, err := r.readAll()
+	if err != nil {
+		return starlark.None, err
 	}
-	return starlark.NewList(vals), nil
+	return rows, nil
 }
 
 // WriteAll writes a csv file to a string
@@ -122,47 +174,47 @@ func WriteAll(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple,
 		buf = &bytes.Buffer{}
 
 		source starlark.Value
-		_comma starlark.String
+		header starlark.Value = starlark.None
+		opts                  = &writeOpts{}
 	)
 
-	if err := starlark.UnpackArgs("write_all", args, kwargs, "source", &source, "comma?", &_comma); err != nil {
+	params := append([]interface{}{"source", &source}, opts.params()...)
+	if err := starlark.UnpackArgs("write_all", args, kwargs, append(params, "header?", &header)...); err != nil {
 		return nil, err
 	}
-
-	csvw := csv.NewWriter(buf)
-	comma := string(_comma)
-	if comma == "" {
-		comma = ","
-	} else if len(comma) != 1 {
-		return starlark.None, fmt.Errorf("expected comma param to be a single-character string")
-	}
-	csvw.Comma = []rune(comma)[0]
-
-	val, err := util.Unmarshal(source)
-	if err != nil {
+	if err := opts.init(); err != nil {
 		return starlark.None, err
 	}
 
-	sl, ok := val.([]interface{})
-	if !ok {
+	rows, ok := source.(starlark.Iterable)
+	if !ok || source.Type() == "string" {
 		return starlark.None, fmt.Errorf("expected value to be an array type")
 	}
 
-	var records [][]string
-	for i, v := range sl {
-		sl, ok := v.([]interface{})
-		if !ok {
-			return starlark.None, fmt.Errorf("row %d is not an array type", i)
+	if header != starlark.None {
+		names, err := stringList("header", header)
+		if err != nil {
+			return starlark.None, err
 		}
-		var row = make([]string, len(sl))
-		for j, v := range sl {
-			row[j] = fmt.Sprintf("%v", v)
+		if err := opts.write(buf, stringFields(names)); err != nil {
+			return starlark.None, err
 		}
-		records = append(records, row)
 	}
 
-	if err := csvw.WriteAll(records); err != nil {
-		return starlark.None, err
+	var (
+		i   int
+		row starlark.Value
+	)
+	iter := rows.Iterate()
+	defer iter.Done()
+	for ; iter.Next(&row); i++ {
+		fields, err := opts.formatRow(i, row)
+		if err != nil {
+			return starlark.None, err
+		}
+		if err := opts.write(buf, fields); err != nil {
+			return starlark.None, fmt.Errorf("row %d: %s", i, err)
+		}
 	}
 
 	return starlark.String(buf.String()), nil
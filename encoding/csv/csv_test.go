@@ -1,6 +1,7 @@
 package csv
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/qri-io/starlib/testdata"
@@ -24,3 +25,43 @@ func TestFile(t *testing.T) {
 		}
 	}
 }
+
+func TestReaderError(t *testing.T) {
+	thread := &starlark.Thread{Load: testdata.NewLoader(LoadModule, ModuleName)}
+	script := `load('encoding/csv.star', 'csv')
+r = csv.reader('a,b\n1,2,3\n4,5\n')
+rows = [row for row in r]
+err = r.error
+`
+	// malformed rows stop iteration without failing the script
+	globals, err := starlark.ExecFile(thread, "reader_error.star", script, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rows := globals["rows"].(*starlark.List); rows.Len() != 1 {
+		t.Errorf("expected 1 row before the malformed row. got: %s", rows)
+	}
+	expect := "csv.reader: record on line 2: wrong number of fields"
+	if e, _ := starlark.AsString(globals["err"]); !strings.Contains(e, expect) {
+		t.Errorf("expected reader error containing %q. got: %s", expect, globals["err"])
+	}
+}
+
+func TestSniffSample(t *testing.T) {
+	short := "a;b\n1;2\n"
+	if got := sniffSample(short); got != short {
+		t.Errorf("expected short sources to be sampled in full. got: %q", got)
+	}
+
+	long := strings.Repeat("aaaa;bbbb;cccc\n", sniffSampleSize/10)
+	sample := sniffSample(long)
+	if len(sample) > sniffSampleSize {
+		t.Errorf("expected sample to be at most %d bytes. got: %d", sniffSampleSize, len(sample))
+	}
+	if !strings.HasSuffix(sample, "cccc") {
+		t.Errorf("expected sample to end on a line boundary. got: %q", sample[len(sample)-20:])
+	}
+	if d := Sniff(sample, sniffDelimiters); d.Delimiter != ';' || d.HasHeader {
+		t.Errorf("unexpected dialect: %+v", d)
+	}
+}
@@ -4,15 +4,16 @@
     csv parses and writes comma-separated values files
     path: encoding/csv
     functions:
-      read_all(source, comma=",", comment="", lazy_quotes=False, trim_leading_space=False, fields_per_record=0, skip=0) [][]string
-        read all rows from a source string, returning a list of string lists
+      read_all(source, comma=",", comment="", lazy_quotes=False, trim_leading_space=False, fields_per_record=0, skip=0, infer_types=False, na_values=[], converters={}, encoding="utf-8", keep_default_na=True) [][]string
+        read all rows from a source string or bytes, returning a list of string lists
         params:
-          source string
-            input string of csv data
+          source string,bytes
+            input csv data
           comma string
             comma is the field delimiter, defaults to "," (a comma).
             comma must be a valid character and must not be \r, \n,
             or the Unicode replacement character (0xFFFD).
+            "auto" detects the delimiter from the start of the source with sniff.
           comment string
             comment, if not "", is the comment character. Lines beginning with the
             comment character without preceding whitespace are ignored.
@@ -36,6 +37,31 @@
             made and records may have a variable number of fields.
           skip int
             number of rows to skip, omitting from returned rows
+          infer_types bool
+            If infer_types is True, cells are converted to int, float, bool, time
+            or None values. Each column gets the narrowest type that fits all of
+            its cells, falling back to strings. Cells listed in na_values are None,
+            numbers with leading zeros stay strings, and dates must use an ISO 8601
+            format such as "2006-01-02" or "2006-01-02T15:04:05Z".
+          na_values list
+            cells that represent missing values in addition to empty cells. cells
+            are matched exactly. missing values are None with infer_types or type
+            name converters, and don't affect inferred column types.
+          converters dict
+            dict of column index to a converter, which is either a type name
+            ("str", "int", "float", "bool" or "date") or a function that accepts the
+            cell string and returns a value. type names other than "str" convert
+            na_values cells to None. converters take precedence over infer_types.
+            conversion errors report the row & column number of the cell.
+          encoding string
+            character encoding of the source. one of "utf-8" (the default),
+            "latin-1", "windows-1252", "utf-16", "utf-16le" or "utf-16be". "utf-16"
+            detects byte order from a byte order mark, defaulting to little endian.
+            UTF-8 and UTF-16 byte order marks are detected with any encoding other
+            than "latin-1" and "windows-1252", and are stripped.
+          keep_default_na bool
+            If keep_default_na is False, empty cells aren't missing values, and
+            only cells listed in na_values are.
         examples:
           basic
             read a csv string into a list of string lists
@@ -49,15 +75,33 @@
               data = csv.read_all(data_str)
               print(data)
               # Output: [["type", "name", "number_of_legs"], ["dog", "spot", "4"], ["cat", "spot", "3"], ["spider", "samantha", "8"]]
-      write_all(source,comma=",") string
-        write all rows from source to a csv-encoded string
+      write_all(source,comma=",",quoting="minimal",lineterminator="\n",null_value="",float_format="",header=None) string
+        write all rows from source to a csv-encoded string. strings are written
+        as-is, ints in decimal, floats in their shortest exact form unless
+        float_format is set, None as null_value and bools as "true" or "false".
+        Other values are written in their go string form.
         params:
-          source [][]string
-            array of arrays of strings to write to csv
+          source [][]any
+            array of arrays of values to write to csv
           comma string
             comma is the field delimiter, defaults to "," (a comma).
             comma must be a valid character and must not be \r, \n,
             or the Unicode replacement character (0xFFFD).
+          quoting string
+            when to quote fields. "minimal" (the default) quotes fields containing
+            the delimiter, a quote or a line break, or starting with white space.
+            "all" quotes every field. "nonnumeric" quotes every field that isn't
+            an int or float. "none" never quotes fields, failing on fields that
+            need quoting. quotes inside quoted fields are doubled.
+          lineterminator string
+            line ending written after each row. one of "\n" (the default), "\r\n"
+            or "\r". use "\r\n" for Excel.
+          null_value string
+            text written for None values, defaults to an empty string
+          float_format string
+            printf-style format for float values, for example "%.2f"
+          header list
+            optional list of strings written as the first row
         examples:
           basic
             write a list of string lists to a csv string
@@ -72,6 +116,138 @@
                 ["spider", "samantha", "8"],
               ]
               csv_str = csv.write_all(data)
+      reader(source, comma=",", comment="", lazy_quotes=False, trim_leading_space=False, fields_per_record=0, skip=0, infer_types=False, na_values=[], converters={}, encoding="utf-8", keep_default_na=True) csv.reader
+        lazily read rows from a source string or bytes. the returned reader is an iterable of string lists that reads a row each time it's advanced, and can only be iterated once. errors such as malformed rows stop iteration, and are available as the reader's error attribute, otherwise error is None. with infer_types column types are inferred from the first 1000 rows, which are read ahead. later cells that don't fit their column's type widen it from that row on, so an int column becomes float and other mismatches become strings. rows that were already read keep their types, use converters to set the types of columns that vary
+        params:
+          source string,bytes
+            input csv data
+          comma string
+            field delimiter, defaults to "," (a comma)
+          comment string
+            comment character. lines beginning with the comment character are ignored
+          lazy_quotes bool
+            If lazy_quotes is True, a quote may appear in an unquoted field and
+            a non-doubled quote may appear in a quoted field.
+          trim_leading_space bool
+            If trim_leading_space is True, leading white space in a field is ignored.
+          fields_per_record int
+            number of expected fields per record, behaves the same as read_all
+          skip int
+            number of rows to skip
+        examples:
+          basic
+            read a column without reading all rows into memory
+            code:
+              load("encoding/csv.star", "csv")
+              data_str = """name,number_of_legs
+              spot,4
+              samantha,8
+              """
+              legs = [int(row[1]) for row in csv.reader(data_str, skip=1)]
+              print(legs)
+              # Output: [4, 8]
+      writer(comma=",",quoting="minimal",lineterminator="\n",null_value="",float_format="") csv.writer
+        create a writer that builds a csv string row-by-row. values are formatted the same as write_all
+        params:
+          comma string
+            comma is the field delimiter, defaults to "," (a comma).
+          quoting string
+            when to quote fields, the same as write_all
+          lineterminator string
+            line ending written after each row
+          null_value string
+            text written for None values
+          float_format string
+            printf-style format for float values
+        examples:
+          basic
+            write rows one at a time
+            code:
+              load("encoding/csv.star", "csv")
+              w = csv.writer()
+              w.writerow(["type", "name"])
+              w.writerows([["dog", "spot"], ["cat", "spot"]])
+              print(w.getvalue().splitlines())
+              # Output: ["type,name", "dog,spot", "cat,spot"]
+      read_dicts(source, fieldnames=None, restkey=None, restval=None, comma=",", comment="", lazy_quotes=False, trim_leading_space=False, fields_per_record=0, skip=0, infer_types=False, na_values=[], converters={}, encoding="utf-8", keep_default_na=True) [{string:string}]
+        read all rows from a source string or bytes, returning a list of dicts keyed by field name. accepts the same arguments as read_all. converters may also be keyed by field name
+        params:
+          source string,bytes
+            input csv data
+          fieldnames list
+            optional. list of field names. if None, the first row (after any skipped rows) is read as the header
+          restkey string
+            key of a list of any values in a row past the last field name. only applies when fields_per_record is negative
+          restval any
+            value of fields missing from a row. only applies when fields_per_record is negative
+        examples:
+          basic
+            read a csv string with a header row
+            code:
+              load("encoding/csv.star", "csv")
+              data_str = """name,number_of_legs
+              spot,4
+              """
+              print(csv.read_dicts(data_str))
+              # Output: [{"name": "spot", "number_of_legs": "4"}]
+      dict_reader(source, fieldnames=None, restkey=None, restval=None, comma=",", comment="", lazy_quotes=False, trim_leading_space=False, fields_per_record=0, skip=0, infer_types=False, na_values=[], converters={}, encoding="utf-8", keep_default_na=True) csv.reader
+        lazily read rows from a source string or bytes as dicts. accepts the same arguments as read_dicts, inferring column types from the first rows like reader. the returned reader can only be iterated once, and records errors like reader
+      write_dicts(rows, fieldnames=None, restval="", extrasaction="raise", comma=",", quoting="minimal", lineterminator="\n", null_value="", float_format="") string
+        write a list of dicts to a csv string, starting with a header row. accepts the same formatting arguments as write_all
+        params:
+          rows [{string:any}]
+            list of dicts to write
+          fieldnames list
+            optional. field names in column order. if None, the keys of the first row are used
+          restval any
+            value written for fields missing from a row
+          extrasaction string
+            how to handle dict keys missing from fieldnames. "raise" (the default) fails, "ignore" skips them
+          comma string
+            field delimiter, defaults to "," (a comma)
+        examples:
+          basic
+            write dicts with a header row
+            code:
+              load("encoding/csv.star", "csv")
+              csv_str = csv.write_dicts([{"name": "spot", "number_of_legs": 4}])
+              print(csv_str.splitlines())
+              # Output: ["name,number_of_legs", "spot,4"]
+      sniff(sample, delimiters=",;\t|") dialect
+        detect the format of csv data from a sample, such as the first few lines of a file. the delimiter is the one that splits the most rows into the same number of fields, preferring earlier delimiters on ties. samples no delimiter splits use the first delimiter
+        params:
+          sample string
+            csv data to examine
+          delimiters string
+            optional. characters to consider as delimiters
+        examples:
+          basic
+            detect a semicolon-delimited file with a header row
+            code:
+              load("encoding/csv.star", "csv")
+              dialect = csv.sniff("name;price\nfoo;1,5\nbar;2,25\n")
+              print(dialect.delimiter, dialect.has_header)
+              # Output: ; True
+    types:
+      csv.writer
+        methods:
+          writerow(row)
+            write a single row. row is a list of values
+          writerows(rows)
+            write each row of an iterable of rows
+          getvalue() string
+            return all written rows as a csv string
+      dialect
+        the format of csv data, as detected by sniff
+        fields:
+          delimiter string
+            field delimiter
+          quotechar string
+            character used to quote fields. readers only support '"'
+          has_header bool
+            whether the first row appears to be a header. a guess, based on whether the types or lengths of the first row's values differ from the rows below it
+          lineterminator string
+            line terminator, one of "\n", "\r\n" or "\r"
 
 
 */
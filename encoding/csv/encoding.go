@@ -0,0 +1,59 @@
+package csv
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"go.starlark.net/starlark"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// encodings maps encoding names to the encoding of csv sources. names are
+// matched case-insensitively
+var encodings = map[string]encoding.Encoding{
+	"utf-8":        encoding.Nop,
+	"utf8":         encoding.Nop,
+	"latin-1":      charmap.ISO8859_1,
+	"latin1":       charmap.ISO8859_1,
+	"iso-8859-1":   charmap.ISO8859_1,
+	"windows-1252": charmap.Windows1252,
+	"cp1252":       charmap.Windows1252,
+	"utf-16":       unicode.UTF16(unicode.LittleEndian, unicode.UseBOM),
+	"utf-16le":     unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM),
+	"utf-16be":     unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM),
+}
+
+// sourceReader returns a reader of UTF-8 text from a string or bytes source.
+// byte order marks are stripped. A UTF-8 or UTF-16 byte order mark takes
+// precedence over the encoding name, except for single-byte encodings where
+// byte order marks aren't meaningful
+func sourceReader(source starlark.Value, name string) (io.Reader, error) {
+	var data []byte
+	switch s := source.(type) {
+	case starlark.String:
+		data = []byte(s)
+	case starlark.Bytes:
+		data = []byte(s)
+	default:
+		return nil, fmt.Errorf("expected source to be a string or bytes. got: '%s'", source.Type())
+	}
+
+	enc := encoding.Nop
+	if name != "" {
+		var ok bool
+		if enc, ok = encodings[strings.ToLower(name)]; !ok {
+			return nil, fmt.Errorf("unknown encoding %q. expected one of \"utf-8\", \"latin-1\", \"windows-1252\", \"utf-16\", \"utf-16le\" or \"utf-16be\"", name)
+		}
+	}
+
+	var dec transform.Transformer = enc.NewDecoder()
+	if _, ok := enc.(*charmap.Charmap); !ok {
+		dec = unicode.BOMOverride(dec)
+	}
+	return transform.NewReader(bytes.NewReader(data), dec), nil
+}
@@ -0,0 +1,182 @@
+package csv
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/qri-io/starlib/util"
+	"go.starlark.net/starlark"
+)
+
+// quoting policies
+const (
+	// quoteMinimal quotes fields that contain delimiters, quotes, line breaks or
+	// leading white space
+	quoteMinimal = "minimal"
+	// quoteAll quotes every field
+	quoteAll = "all"
+	// quoteNonNumeric quotes every field that isn't an int or float value
+	quoteNonNumeric = "nonnumeric"
+	// quoteNone never quotes fields. writing a field that needs quoting fails
+	quoteNone = "none"
+)
+
+// writeOpts are the arguments shared by functions that write csv data
+type writeOpts struct {
+	comma          starlark.String
+	quoting        string
+	lineTerminator string
+	nullValue      string
+	floatFormat    string
+
+	delim rune
+}
+
+// params returns name, value pairs for starlark.UnpackArgs
+func (o *writeOpts) params() []interface{} {
+	return []interface{}{
+		"comma?", &o.comma,
+		"quoting?", &o.quoting,
+		"lineterminator?", &o.lineTerminator,
+		"null_value?", &o.nullValue,
+		"float_format?", &o.floatFormat,
+	}
+}
+
+// init validates arguments after unpacking, setting defaults
+func (o *writeOpts) init() error {
+	var err error
+	if o.delim, err = delimiter(o.comma); err != nil {
+		return err
+	}
+
+	switch o.quoting {
+	case "":
+		o.quoting = quoteMinimal
+	case quoteMinimal, quoteAll, quoteNonNumeric, quoteNone:
+	default:
+		return fmt.Errorf("expected quoting to be one of %q, %q, %q or %q. got: %q", quoteMinimal, quoteAll, quoteNonNumeric, quoteNone, o.quoting)
+	}
+
+	switch o.lineTerminator {
+	case "":
+		o.lineTerminator = "\n"
+	case "\n", "\r\n", "\r":
+	default:
+		return fmt.Errorf("expected lineterminator to be \"\\n\", \"\\r\\n\" or \"\\r\". got: %q", o.lineTerminator)
+	}
+
+	if o.floatFormat != "" {
+		if s := fmt.Sprintf(o.floatFormat, 1.0); strings.Contains(s, "%!") {
+			return fmt.Errorf("invalid float_format %q", o.floatFormat)
+		}
+	}
+	return nil
+}
+
+// field is a formatted csv value
+type field struct {
+	text    string
+	numeric bool
+}
+
+// format converts a starlark value to a csv field. None is written as the
+// null value, floats use the float format if one is set
+func (o *writeOpts) format(v starlark.Value) (field, error) {
+	switch x := v.(type) {
+	case starlark.NoneType:
+		return field{text: o.nullValue}, nil
+	case starlark.String:
+		return field{text: string(x)}, nil
+	case starlark.Int:
+		return field{text: x.String(), numeric: true}, nil
+	case starlark.Float:
+		if o.floatFormat != "" {
+			return field{text: fmt.Sprintf(o.floatFormat, float64(x)), numeric: true}, nil
+		}
+		return field{text: fmt.Sprintf("%v", float64(x)), numeric: true}, nil
+	}
+	val, err := util.Unmarshal(v)
+	if err != nil {
+		return field{}, err
+	}
+	return field{text: fmt.Sprintf("%v", val)}, nil
+}
+
+// formatRow converts row i to csv fields. rows must be lists or tuples
+func (o *writeOpts) formatRow(i int, row starlark.Value) ([]field, error) {
+	switch row.(type) {
+	case *starlark.List, starlark.Tuple:
+	default:
+		return nil, fmt.Errorf("row %d is not an array type", i)
+	}
+	seq := row.(starlark.Indexable)
+	fields := make([]field, seq.Len())
+	for j := range fields {
+		f, err := o.format(seq.Index(j))
+		if err != nil {
+			return nil, fmt.Errorf("row %d, column %d: %s", i, j+1, err)
+		}
+		fields[j] = f
+	}
+	return fields, nil
+}
+
+// stringFields converts strings to non-numeric fields
+func stringFields(strs []string) []field {
+	fields := make([]field, len(strs))
+	for i, s := range strs {
+		fields[i] = field{text: s}
+	}
+	return fields
+}
+
+// write writes a record to buf, quoting fields according to the quoting
+// policy
+func (o *writeOpts) write(buf *bytes.Buffer, fields []field) error {
+	for j, f := range fields {
+		if j > 0 {
+			buf.WriteRune(o.delim)
+		}
+
+		quote := false
+		switch o.quoting {
+		case quoteAll:
+			quote = true
+		case quoteNonNumeric:
+			quote = !f.numeric
+		case quoteMinimal:
+			quote = o.needsQuotes(f.text)
+		case quoteNone:
+			if o.needsQuotes(f.text) {
+				return fmt.Errorf("field %q requires quoting, which quoting=%q doesn't allow", f.text, quoteNone)
+			}
+		}
+
+		if !quote {
+			buf.WriteString(f.text)
+			continue
+		}
+		buf.WriteByte('"')
+		buf.WriteString(strings.Replace(f.text, `"`, `""`, -1))
+		buf.WriteByte('"')
+	}
+	buf.WriteString(o.lineTerminator)
+	return nil
+}
+
+// needsQuotes reports whether a field must be quoted to be read back
+// correctly, following the same rules as go's encoding/csv writer
+func (o *writeOpts) needsQuotes(text string) bool {
+	if text == "" {
+		return false
+	}
+	if text == `\.` || strings.ContainsRune(text, o.delim) || strings.ContainsAny(text, "\"\r\n") {
+		return true
+	}
+	r, _ := utf8.DecodeRuneInString(text)
+	return unicode.IsSpace(r)
+}
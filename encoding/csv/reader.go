@@ -0,0 +1,359 @@
+package csv
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+
+	"go.starlark.net/starlark"
+)
+
+// Reader is a starlark iterable that lazily reads rows from a csv source,
+// yielding each row as a list of strings, or as a dict when the reader has
+// dictOpts. A Reader can only be iterated once. Iteration stops at the first
+// error, which scripts can check with the error attribute
+type Reader struct {
+	thread *starlark.Thread
+	csvr   *csv.Reader
+	opts   *readOpts
+	dicts  *dictOpts
+	// row is the number of records read from the source, including skipped
+	// rows and the header
+	row  int
+	done bool
+	err  error
+
+	// sample holds records read ahead to infer column types, which are
+	// yielded before reading more. sampleErr is the error that ended the
+	// sample, returned once the sample is used up
+	sampled   bool
+	sample    []sampleRecord
+	sampleErr error
+	types     []cellType
+}
+
+// sampleRecord is a record read ahead of iteration, with its row number
+type sampleRecord struct {
+	cells []string
+	row   int
+}
+
+// inferSampleSize is the number of records lazy readers read ahead to infer
+// column types
+const inferSampleSize = 1000
+
+// dictOpts are the arguments of functions that read rows as dicts
+type dictOpts struct {
+	fieldnames starlark.Value
+	restkey    starlark.Value
+	restval    starlark.Value
+
+	names []string
+}
+
+// params returns name, value pairs for starlark.UnpackArgs
+func (o *dictOpts) params() []interface{} {
+	return []interface{}{
+		"fieldnames?", &o.fieldnames,
+		"restkey?", &o.restkey,
+		"restval?", &o.restval,
+	}
+}
+
+// init validates arguments after unpacking
+func (o *dictOpts) init() error {
+	if o.restkey == nil {
+		o.restkey = starlark.None
+	}
+	if o.restval == nil {
+		o.restval = starlark.None
+	}
+	if o.fieldnames == nil || o.fieldnames == starlark.None {
+		return nil
+	}
+	names, err := stringList("fieldnames", o.fieldnames)
+	if err != nil {
+		return err
+	}
+	o.names = names
+	return nil
+}
+
+// dict keys a record by fieldnames. values past the last field name are
+// collected in a list under restkey, missing values are set to restval
+func (o *dictOpts) dict(record []starlark.Value) (*starlark.Dict, error) {
+	d := starlark.NewDict(len(o.names))
+	for i, name := range o.names {
+		var v starlark.Value = o.restval
+		if i < len(record) {
+			v = record[i]
+		}
+		if err := d.SetKey(starlark.String(name), v); err != nil {
+			return nil, err
+		}
+	}
+	if len(record) > len(o.names) {
+		if err := d.SetKey(o.restkey, starlark.NewList(record[len(o.names):])); err != nil {
+			return nil, err
+		}
+	}
+	return d, nil
+}
+
+// stringList converts an iterable of strings to a string slice
+func stringList(param string, v starlark.Value) ([]string, error) {
+	iterable, ok := v.(starlark.Iterable)
+	if !ok {
+		return nil, fmt.Errorf("expected %s to be a list of strings. got: '%s'", param, v.Type())
+	}
+	var (
+		strs []string
+		x    starlark.Value
+	)
+	iter := iterable.Iterate()
+	defer iter.Done()
+	for iter.Next(&x) {
+		s, ok := starlark.AsString(x)
+		if !ok {
+			return nil, fmt.Errorf("expected %s to be a list of strings. got element of type '%s'", param, x.Type())
+		}
+		strs = append(strs, s)
+	}
+	return strs, nil
+}
+
+var (
+	_ starlark.Value    = (*Reader)(nil)
+	_ starlark.Iterable = (*Reader)(nil)
+	_ starlark.HasAttrs = (*Reader)(nil)
+)
+
+// NewReader creates a Reader from a csv source, accepting the same arguments
+// as ReadAll
+func NewReader(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	opts := &readOpts{keepDefaultNA: true}
+	if err := starlark.UnpackArgs("reader", args, kwargs, opts.params()...); err != nil {
+		return nil, err
+	}
+	r, err := opts.newReader(thread, nil)
+	if err != nil {
+		return starlark.None, err
+	}
+	return r, nil
+}
+
+// NewDictReader creates a Reader that yields rows as dicts keyed by
+// fieldnames. When fieldnames is None the first row is read as the header
+func NewDictReader(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	r, err := newDictReader("dict_reader", thread, args, kwargs)
+	if err != nil {
+		return starlark.None, err
+	}
+	return r, nil
+}
+
+// ReadDicts reads all rows from a csv source as a list of dicts keyed by
+// fieldnames. When fieldnames is None the first row is read as the header
+func ReadDicts(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	r, err := newDictReader("read_dicts", thread, args, kwargs)
+	if err != nil {
+		return starlark.None, err
+	}
+	return r.readAll()
+}
+
+func newDictReader(fnname string, thread *starlark.Thread, args starlark.Tuple, kwargs []starlark.Tuple) (*Reader, error) {
+	var (
+		opts  = &readOpts{keepDefaultNA: true}
+		dicts = &dictOpts{}
+	)
+	if err := starlark.UnpackArgs(fnname, args, kwargs, append(opts.params(), dicts.params()...)...); err != nil {
+		return nil, err
+	}
+	if err := dicts.init(); err != nil {
+		return nil, fmt.Errorf("%s: %s", fnname, err)
+	}
+	return opts.newReader(thread, dicts)
+}
+
+// newReader creates a Reader for the source. rows are read as dicts when
+// dicts is non-nil
+func (o *readOpts) newReader(thread *starlark.Thread, dicts *dictOpts) (*Reader, error) {
+	if err := o.initNulls(); err != nil {
+		return nil, err
+	}
+	if err := o.initConverters(dicts != nil); err != nil {
+		return nil, err
+	}
+	csvr, err := o.reader()
+	if err != nil {
+		return nil, err
+	}
+	return &Reader{thread: thread, csvr: csvr, opts: o, dicts: dicts, row: o.skip}, nil
+}
+
+// String implements the starlark.Value interface
+func (r *Reader) String() string { return "<csv.reader>" }
+
+// Type implements the starlark.Value interface
+func (r *Reader) Type() string { return "csv.reader" }
+
+// Freeze implements the starlark.Value interface
+func (r *Reader) Freeze() {}
+
+// Truth implements the starlark.Value interface
+func (r *Reader) Truth() starlark.Bool { return starlark.True }
+
+// Hash implements the starlark.Value interface
+func (r *Reader) Hash() (uint32, error) { return 0, fmt.Errorf("unhashable type: csv.reader") }
+
+// Attr implements the starlark.HasAttrs interface. error is the error that
+// stopped iteration, or None
+func (r *Reader) Attr(name string) (starlark.Value, error) {
+	if name != "error" {
+		return nil, nil
+	}
+	if r.err == nil {
+		return starlark.None, nil
+	}
+	return starlark.String(fmt.Sprintf("csv.reader: %s", r.err)), nil
+}
+
+// AttrNames implements the starlark.HasAttrs interface
+func (r *Reader) AttrNames() []string { return []string{"error"} }
+
+// Iterate implements the starlark.Iterable interface
+func (r *Reader) Iterate() starlark.Iterator { return r }
+
+// Next implements the starlark.Iterator interface. starlark iterators can't
+// return errors, so errors stop iteration and are recorded on the reader
+func (r *Reader) Next(p *starlark.Value) bool {
+	if r.done {
+		return false
+	}
+	row, err := r.next()
+	if err != nil {
+		r.done = true
+		if err != io.EOF {
+			r.err = err
+		}
+		return false
+	}
+	*p = row
+	return true
+}
+
+// next reads and converts a row, returning io.EOF when there are no more
+// rows. When inferring types, column types are inferred from the first
+// inferSampleSize records. later cells that don't fit their column's type
+// widen it, leaving rows that were already read as they are
+func (r *Reader) next() (starlark.Value, error) {
+	if r.opts.inferTypes && !r.sampled {
+		r.readSample()
+	}
+	if len(r.sample) > 0 {
+		rec := r.sample[0]
+		r.sample = r.sample[1:]
+		return r.convert(rec.cells, rec.row, r.types)
+	}
+	if r.sampleErr != nil {
+		return nil, r.sampleErr
+	}
+	record, row, err := r.read()
+	if err != nil {
+		return nil, err
+	}
+	if r.opts.inferTypes {
+		r.types = r.opts.nulls.widen(r.types, record)
+	}
+	return r.convert(record, row, r.types)
+}
+
+// readSample reads ahead up to inferSampleSize records and infers column
+// types from them
+func (r *Reader) readSample() {
+	r.sampled = true
+	records := make([][]string, 0, inferSampleSize)
+	for len(records) < inferSampleSize {
+		record, row, err := r.read()
+		if err != nil {
+			r.sampleErr = err
+			break
+		}
+		records = append(records, record)
+		r.sample = append(r.sample, sampleRecord{cells: record, row: row})
+	}
+	r.types = r.opts.nulls.inferColumns(records)
+}
+
+// read reads a record, returning it with its 1-based row number. dict readers
+// without fieldnames read the header before the first record
+func (r *Reader) read() ([]string, int, error) {
+	if r.dicts != nil && r.dicts.names == nil {
+		header, err := r.csvr.Read()
+		if err != nil {
+			return nil, 0, err
+		}
+		r.row++
+		r.dicts.names = header
+	}
+	record, err := r.csvr.Read()
+	if err != nil {
+		return nil, 0, err
+	}
+	r.row++
+	return record, r.row, nil
+}
+
+// readAll reads all remaining rows. types are inferred per-column
+func (r *Reader) readAll() (*starlark.List, error) {
+	var (
+		records [][]string
+		rows    []int
+	)
+	for {
+		record, row, err := r.read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+		rows = append(rows, row)
+	}
+
+	var types []cellType
+	if r.opts.inferTypes {
+		types = r.opts.nulls.inferColumns(records)
+	}
+	vals := make([]starlark.Value, len(records))
+	for i, record := range records {
+		v, err := r.convert(record, rows[i], types)
+		if err != nil {
+			return nil, err
+		}
+		vals[i] = v
+	}
+	return starlark.NewList(vals), nil
+}
+
+// convert converts a record to a starlark list or dict, applying converters
+// and type inference. types are column types, cells in columns without a
+// type are inferred separately
+func (r *Reader) convert(record []string, row int, types []cellType) (starlark.Value, error) {
+	var names []string
+	if r.dicts != nil {
+		names = r.dicts.names
+	}
+	vals, err := r.opts.convertRecord(r.thread, record, row, names, types)
+	if err != nil {
+		return nil, err
+	}
+	if r.dicts == nil {
+		return starlark.NewList(vals), nil
+	}
+	return r.dicts.dict(vals)
+}
+
+// Done implements the starlark.Iterator interface
+func (r *Reader) Done() {}
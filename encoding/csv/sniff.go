@@ -0,0 +1,195 @@
+package csv
+
+import (
+	"encoding/csv"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/qri-io/starlib/util/replacecr"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+)
+
+// sniffDelimiters are the delimiters sniff considers by default, in order of
+// preference when several fit a sample equally well
+const sniffDelimiters = ",;\t|"
+
+// sniffRows is the maximum number of rows sniff reads from a sample
+const sniffRows = 100
+
+// sniffSampleSize is the number of bytes of a source read with comma="auto"
+// that are used to detect the delimiter
+const sniffSampleSize = 64 * 1024
+
+// Dialect describes the format of csv data
+type Dialect struct {
+	Delimiter      rune
+	QuoteChar      rune
+	HasHeader      bool
+	LineTerminator string
+}
+
+// Struct returns the dialect as a starlark Struct
+func (d Dialect) Struct() *starlarkstruct.Struct {
+	return starlarkstruct.FromStringDict(starlark.String("dialect"), starlark.StringDict{
+		"delimiter":      starlark.String(string(d.Delimiter)),
+		"quotechar":      starlark.String(string(d.QuoteChar)),
+		"has_header":     starlark.Bool(d.HasHeader),
+		"lineterminator": starlark.String(d.LineTerminator),
+	})
+}
+
+// SniffFunc detects the dialect of a csv sample: sniff(sample, delimiters=",;\t|")
+func SniffFunc(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var (
+		sample     string
+		delimiters = sniffDelimiters
+	)
+	if err := starlark.UnpackArgs("sniff", args, kwargs, "sample", &sample, "delimiters?", &delimiters); err != nil {
+		return nil, err
+	}
+	if delimiters == "" {
+		return starlark.None, fmt.Errorf("sniff: delimiters must not be empty")
+	}
+	return Sniff(sample, delimiters).Struct(), nil
+}
+
+// Sniff detects the dialect of a csv sample, choosing the delimiter from
+// delimiters that splits rows into a consistent number of fields. Samples
+// that no delimiter splits use the first delimiter
+func Sniff(sample, delimiters string) Dialect {
+	d := Dialect{
+		Delimiter:      sniffDelimiter(sample, delimiters),
+		QuoteChar:      '"',
+		LineTerminator: "\n",
+	}
+	if strings.Contains(sample, "\r\n") {
+		d.LineTerminator = "\r\n"
+	} else if strings.Contains(sample, "\r") {
+		d.LineTerminator = "\r"
+	}
+	if !strings.ContainsRune(sample, '"') && singleQuoted(sample, d.Delimiter) {
+		d.QuoteChar = '\''
+	}
+	d.HasHeader = hasHeader(sniffRecords(sample, d.Delimiter))
+	return d
+}
+
+// sniffDelimiter picks the delimiter that splits the most rows into the same
+// number of fields, preferring earlier delimiters on ties
+func sniffDelimiter(sample, delimiters string) rune {
+	var (
+		best      = []rune(delimiters)[0]
+		bestScore float64
+	)
+	for _, delim := range delimiters {
+		records := sniffRecords(sample, delim)
+		if len(records) == 0 {
+			continue
+		}
+		counts := map[int]int{}
+		mode := 0
+		for _, record := range records {
+			counts[len(record)]++
+			if counts[len(record)] > counts[mode] {
+				mode = len(record)
+			}
+		}
+		if mode < 2 {
+			continue
+		}
+		if score := float64(counts[mode]) / float64(len(records)); score > bestScore {
+			best, bestScore = delim, score
+		}
+	}
+	return best
+}
+
+// sniffRecords leniently parses up to sniffRows rows of a sample
+func sniffRecords(sample string, delim rune) [][]string {
+	if !validDelim(delim) {
+		return nil
+	}
+	r := csv.NewReader(replacecr.Reader(strings.NewReader(sample)))
+	r.Comma = delim
+	r.LazyQuotes = true
+	r.FieldsPerRecord = -1
+
+	var records [][]string
+	for len(records) < sniffRows {
+		record, err := r.Read()
+		if err != nil {
+			break
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func validDelim(r rune) bool {
+	return !(r == 0 || r == '"' || r == '\r' || r == '\n' || !utf8.ValidRune(r) || r == utf8.RuneError)
+}
+
+// singleQuoted reports whether a sample quotes fields with single quotes
+func singleQuoted(sample string, delim rune) bool {
+	for _, line := range strings.Split(strings.Replace(sample, "\r", "\n", -1), "\n") {
+		for _, field := range strings.Split(line, string(delim)) {
+			field = strings.TrimSpace(field)
+			if len(field) > 1 && field[0] == '\'' && field[len(field)-1] == '\'' {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasHeader guesses whether the first record is a header by comparing it to
+// the records that follow, column by column. A column votes for a header when
+// its cells share a type that the first cell doesn't have, or, for string
+// columns, a length the first cell doesn't have
+func hasHeader(records [][]string) bool {
+	if len(records) < 2 {
+		return false
+	}
+	header, rows := records[0], records[1:]
+	types := defaultNullValues.inferColumns(rows)
+
+	votes := 0
+	for j, name := range header {
+		if j >= len(types) {
+			break
+		}
+		switch t := types[j]; t {
+		case typeNull:
+			continue
+		case typeString:
+			length := -1
+			for _, row := range rows {
+				if j >= len(row) || defaultNullValues[row[j]] {
+					continue
+				}
+				if length == -1 {
+					length = len(row[j])
+				} else if len(row[j]) != length {
+					length = -2
+					break
+				}
+			}
+			if length >= 0 {
+				if len(name) != length {
+					votes++
+				} else {
+					votes--
+				}
+			}
+		default:
+			if mergeTypes(t, defaultNullValues.infer(name)) != t {
+				votes++
+			} else {
+				votes--
+			}
+		}
+	}
+	return votes > 0
+}
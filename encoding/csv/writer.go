@@ -0,0 +1,193 @@
+package csv
+
+import (
+	"bytes"
+	"fmt"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+)
+
+// Writer incrementally writes rows to an in-memory csv string
+type Writer struct {
+	buf  *bytes.Buffer
+	opts *writeOpts
+	rows int
+}
+
+// NewWriter creates a Writer, accepting the same formatting arguments as
+// WriteAll: writer(comma=",", quoting="minimal", lineterminator="\n",
+// null_value="", float_format="")
+func NewWriter(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	opts := &writeOpts{}
+	if err := starlark.UnpackArgs("writer", args, kwargs, opts.params()...); err != nil {
+		return nil, err
+	}
+	if err := opts.init(); err != nil {
+		return starlark.None, err
+	}
+	w := &Writer{buf: &bytes.Buffer{}, opts: opts}
+	return w.Struct(), nil
+}
+
+// Struct returns the writer's methods as a starlark Struct
+func (w *Writer) Struct() *starlarkstruct.Struct {
+	return starlarkstruct.FromStringDict(starlark.String("csv.writer"), starlark.StringDict{
+		"writerow":  starlark.NewBuiltin("writerow", w.writerow),
+		"writerows": starlark.NewBuiltin("writerows", w.writerows),
+		"getvalue":  starlark.NewBuiltin("getvalue", w.getvalue),
+	})
+}
+
+func (w *Writer) writerow(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var row starlark.Value
+	if err := starlark.UnpackArgs("writerow", args, kwargs, "row", &row); err != nil {
+		return nil, err
+	}
+	if err := w.write(row); err != nil {
+		return starlark.None, err
+	}
+	return starlark.None, nil
+}
+
+func (w *Writer) writerows(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var rows starlark.Iterable
+	if err := starlark.UnpackArgs("writerows", args, kwargs, "rows", &rows); err != nil {
+		return nil, err
+	}
+	iter := rows.Iterate()
+	defer iter.Done()
+	var row starlark.Value
+	for iter.Next(&row) {
+		if err := w.write(row); err != nil {
+			return starlark.None, err
+		}
+	}
+	return starlark.None, nil
+}
+
+func (w *Writer) getvalue(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if err := starlark.UnpackArgs("getvalue", args, kwargs); err != nil {
+		return nil, err
+	}
+	return starlark.String(w.buf.String()), nil
+}
+
+func (w *Writer) write(row starlark.Value) error {
+	fields, err := w.opts.formatRow(w.rows, row)
+	if err != nil {
+		return err
+	}
+	if err := w.opts.write(w.buf, fields); err != nil {
+		return fmt.Errorf("row %d: %s", w.rows, err)
+	}
+	w.rows++
+	return nil
+}
+
+// WriteDicts writes a list of dicts to a csv string, starting with a header
+// row of fieldnames. When fieldnames is None the keys of the first row are
+// used
+func WriteDicts(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var (
+		rows         starlark.Iterable
+		fieldnames   starlark.Value = starlark.None
+		restval      starlark.Value = starlark.String("")
+		extrasaction                = "raise"
+		opts                        = &writeOpts{}
+	)
+	params := append([]interface{}{
+		"rows", &rows,
+		"fieldnames?", &fieldnames,
+		"restval?", &restval,
+		"extrasaction?", &extrasaction,
+	}, opts.params()...)
+	if err := starlark.UnpackArgs("write_dicts", args, kwargs, params...); err != nil {
+		return nil, err
+	}
+	if extrasaction != "raise" && extrasaction != "ignore" {
+		return starlark.None, fmt.Errorf("write_dicts: extrasaction must be \"raise\" or \"ignore\". got: %q", extrasaction)
+	}
+	if err := opts.init(); err != nil {
+		return starlark.None, err
+	}
+
+	var names []string
+	if fieldnames != starlark.None {
+		var err error
+		if names, err = stringList("fieldnames", fieldnames); err != nil {
+			return starlark.None, fmt.Errorf("write_dicts: %s", err)
+		}
+	}
+
+	var (
+		buf = &bytes.Buffer{}
+		i   int
+		row starlark.Value
+	)
+	iter := rows.Iterate()
+	defer iter.Done()
+	for ; iter.Next(&row); i++ {
+		d, ok := row.(*starlark.Dict)
+		if !ok {
+			return starlark.None, fmt.Errorf("row %d is not a dict type", i)
+		}
+		if names == nil {
+			for _, k := range d.Keys() {
+				name, ok := starlark.AsString(k)
+				if !ok {
+					return starlark.None, fmt.Errorf("row %d: expected dict keys to be strings. got: '%s'", i, k.Type())
+				}
+				names = append(names, name)
+			}
+		}
+		if i == 0 {
+			if err := opts.write(buf, stringFields(names)); err != nil {
+				return starlark.None, err
+			}
+		}
+
+		if extrasaction == "raise" {
+			for _, k := range d.Keys() {
+				if name, ok := starlark.AsString(k); !ok || !contains(names, name) {
+					return starlark.None, fmt.Errorf("row %d: dict contains fields not in fieldnames: %s", i, k)
+				}
+			}
+		}
+
+		record := make([]field, len(names))
+		for j, name := range names {
+			v, found, err := d.Get(starlark.String(name))
+			if err != nil {
+				return starlark.None, err
+			}
+			if !found {
+				v = restval
+			}
+			if record[j], err = opts.format(v); err != nil {
+				return starlark.None, fmt.Errorf("row %d, field %q: %s", i, name, err)
+			}
+		}
+		if err := opts.write(buf, record); err != nil {
+			return starlark.None, fmt.Errorf("row %d: %s", i, err)
+		}
+	}
+
+	// write a header for empty input when fieldnames are known
+	if i == 0 && names != nil {
+		if err := opts.write(buf, stringFields(names)); err != nil {
+			return starlark.None, err
+		}
+	}
+
+	return starlark.String(buf.String()), nil
+}
+
+func contains(strs []string, s string) bool {
+	for _, str := range strs {
+		if str == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,283 @@
+package http
+
+import (
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.starlark.net/starlark"
+)
+
+// RequestRecord describes a request made by a starlark thread
+type RequestRecord struct {
+	Method string
+	URL    string
+	// StatusCode is the response status. zero if no response was received
+	StatusCode int
+	// RequestBytes is the size of the request body
+	RequestBytes int64
+	// ResponseBytes is the number of response body bytes read from the
+	// network. responses served from the cache without a request read none
+	ResponseBytes int64
+	// Duration is the time between sending the request and finishing reading
+	// the response body
+	Duration  time.Duration
+	FromCache bool
+	// Err is the error that ended the request, if any
+	Err error
+}
+
+// RequestObserver receives a record of each request a module sends. Set the
+// package-level Observer before calling LoadModule to observe requests.
+// Each retry attempt, redirect and token request is a separate request.
+// Requests are observed when the response body has been read to the end,
+// fails or is closed, or immediately if no response is received. Redirects
+// denied by the module's RequestGuard are observed with their error. Call
+// CloseResponses once a thread is done to observe responses the thread never
+// finished reading. Observers are called on the goroutine executing the
+// thread. Requests made with get_all are observed once get_all finishes.
+// AuditLog implements RequestObserver
+type RequestObserver interface {
+	Observe(thread *starlark.Thread, rec RequestRecord)
+}
+
+// AuditLog is a RequestObserver that collects a summary of the requests each
+// thread makes. Retrieve the summary with AuditSummaryFor after execution
+type AuditLog struct{}
+
+var _ RequestObserver = AuditLog{}
+
+// AuditSummary summarizes the requests made by a thread
+type AuditSummary struct {
+	// Requests lists each observed request in the order it completed
+	Requests []RequestRecord
+	// URLs lists the distinct urls requested, in the order they were first
+	// requested
+	URLs          []string
+	Errors        int
+	RequestBytes  int64
+	ResponseBytes int64
+}
+
+// keyAuditSummary is the thread-local key for a thread's audit summary
+const keyAuditSummary = "http.auditSummary"
+
+type auditSummary struct {
+	lk   sync.Mutex
+	seen map[string]bool
+	AuditSummary
+}
+
+// Observe implements the RequestObserver interface
+func (AuditLog) Observe(thread *starlark.Thread, rec RequestRecord) {
+	if thread == nil {
+		return
+	}
+	localsLk.Lock()
+	s, _ := thread.Local(keyAuditSummary).(*auditSummary)
+	if s == nil {
+		s = &auditSummary{seen: map[string]bool{}}
+		thread.SetLocal(keyAuditSummary, s)
+	}
+	localsLk.Unlock()
+
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	s.Requests = append(s.Requests, rec)
+	if !s.seen[rec.URL] {
+		s.seen[rec.URL] = true
+		s.URLs = append(s.URLs, rec.URL)
+	}
+	if rec.Err != nil {
+		s.Errors++
+	}
+	s.RequestBytes += rec.RequestBytes
+	s.ResponseBytes += rec.ResponseBytes
+}
+
+// AuditSummaryFor returns a copy of the requests an AuditLog observed for a
+// thread
+func AuditSummaryFor(thread *starlark.Thread) AuditSummary {
+	localsLk.Lock()
+	s, _ := thread.Local(keyAuditSummary).(*auditSummary)
+	localsLk.Unlock()
+	if s == nil {
+		return AuditSummary{}
+	}
+
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	sum := s.AuditSummary
+	sum.Requests = append([]RequestRecord(nil), s.Requests...)
+	sum.URLs = append([]string(nil), s.URLs...)
+	return sum
+}
+
+// keyOpenBodies is the thread-local key for observed response bodies a
+// thread hasn't finished reading
+const keyOpenBodies = "http.openBodies"
+
+type openBodies struct {
+	lk     sync.Mutex
+	bodies map[*observedBody]struct{}
+}
+
+// openBodiesFor returns a thread's open bodies, creating the set if it doesn't
+// exist. must be called on the thread's goroutine
+func openBodiesFor(thread *starlark.Thread) *openBodies {
+	localsLk.Lock()
+	defer localsLk.Unlock()
+	open, _ := thread.Local(keyOpenBodies).(*openBodies)
+	if open == nil {
+		open = &openBodies{bodies: map[*observedBody]struct{}{}}
+		thread.SetLocal(keyOpenBodies, open)
+	}
+	return open
+}
+
+// CloseResponses closes response bodies a thread hasn't finished reading,
+// delivering their records to the module's RequestObserver. Hosts using an
+// observer should call CloseResponses after a thread is done
+func CloseResponses(thread *starlark.Thread) {
+	localsLk.Lock()
+	open, _ := thread.Local(keyOpenBodies).(*openBodies)
+	localsLk.Unlock()
+	if open == nil {
+		return
+	}
+
+	open.lk.Lock()
+	bodies := make([]*observedBody, 0, len(open.bodies))
+	for b := range open.bodies {
+		bodies = append(bodies, b)
+	}
+	open.lk.Unlock()
+	for _, b := range bodies {
+		b.Close()
+	}
+}
+
+// keyRevalidating marks the context of a request that revalidates a cached
+// response. a Not Modified response is observed as coming from the cache
+type keyRevalidating struct{}
+
+// observedTransport observes each round trip of requests that carry thread
+// state, including retried attempts, redirects and token requests
+type observedTransport struct {
+	m    *Module
+	next http.RoundTripper
+}
+
+// RoundTrip implements the http.RoundTripper interface
+func (t *observedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	next := t.next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	st := stateFrom(req.Context())
+	if st == nil {
+		return next.RoundTrip(req)
+	}
+	start := time.Now()
+	res, err := next.RoundTrip(req)
+	if err != nil {
+		t.m.observe(st, req, nil, start, false, err)
+		return nil, err
+	}
+	revalidated := req.Context().Value(keyRevalidating{}) != nil && res.StatusCode == http.StatusNotModified
+	t.m.observe(st, req, res, start, revalidated, nil)
+	return res, nil
+}
+
+// observe reports a request to the module's observer, if one is set. records
+// of responses are delivered once the returned body is finished, records of
+// failed requests are delivered immediately
+func (m *Module) observe(st *threadState, req *http.Request, res *http.Response, start time.Time, fromCache bool, err error) {
+	if m.observer == nil {
+		return
+	}
+	rec := RequestRecord{
+		Method:    req.Method,
+		URL:       req.URL.String(),
+		FromCache: fromCache,
+		Err:       err,
+	}
+	if req.ContentLength > 0 {
+		rec.RequestBytes = req.ContentLength
+	}
+	if res == nil {
+		rec.Duration = time.Since(start)
+		m.deliver(st, rec)
+		return
+	}
+
+	rec.StatusCode = res.StatusCode
+	if res.Request != nil {
+		rec.URL = res.Request.URL.String()
+	}
+	b := &observedBody{ReadCloser: res.Body, m: m, st: st, start: start, rec: rec}
+	if st.open != nil {
+		st.open.lk.Lock()
+		st.open.bodies[b] = struct{}{}
+		st.open.lk.Unlock()
+	}
+	res.Body = b
+}
+
+// deliver passes a record to the module's observer, queueing it if the
+// thread state is deferring observations
+func (m *Module) deliver(st *threadState, rec RequestRecord) {
+	st.lk.Lock()
+	if st.deferring {
+		st.deferred = append(st.deferred, rec)
+		st.lk.Unlock()
+		return
+	}
+	st.lk.Unlock()
+	m.observer.Observe(st.thread, rec)
+}
+
+// observedBody counts the bytes read from a response body, delivering the
+// request record when the body is finished
+type observedBody struct {
+	io.ReadCloser
+	m     *Module
+	st    *threadState
+	start time.Time
+
+	once sync.Once
+	rec  RequestRecord
+}
+
+// Read implements the io.Reader interface
+func (b *observedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.rec.ResponseBytes += int64(n)
+	if err == io.EOF {
+		b.finish(nil)
+	} else if err != nil {
+		b.finish(err)
+	}
+	return n, err
+}
+
+// Close implements the io.Closer interface
+func (b *observedBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.finish(nil)
+	return err
+}
+
+func (b *observedBody) finish(err error) {
+	b.once.Do(func() {
+		if open := b.st.open; open != nil {
+			open.lk.Lock()
+			delete(open.bodies, b)
+			open.lk.Unlock()
+		}
+		b.rec.Duration = time.Since(b.start)
+		b.rec.Err = err
+		b.m.deliver(b.st, b.rec)
+	})
+}
@@ -0,0 +1,170 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/qri-io/starlib/testdata"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarktest"
+)
+
+func TestAuditLog(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+		fmt.Fprint(w, strings.Repeat("x", 100))
+	}))
+	defer ts.Close()
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	prev := Observer
+	Observer = AuditLog{}
+	defer func() { Observer = prev }()
+
+	thread := &starlark.Thread{Load: testdata.NewLoader(LoadModule, ModuleName)}
+	starlarktest.SetReporter(thread, t)
+	script := fmt.Sprintf(`load('http.star', 'http')
+load('assert.star', 'assert')
+assert.eq(len(http.get(%[1]q + "/a").body()), 100)
+http.post(%[1]q + "/b", body="hello")
+assert.eq(http.get(%[1]q + "/missing").status_code, 404)
+assert.eq([r.error != None for r in http.get_all([%[1]q + "/a", %[2]q])], [False, True])
+`, ts.URL, closed.URL)
+	if _, err := starlark.ExecFile(thread, "audit.star", script, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// only finished responses have been observed
+	if got := len(AuditSummaryFor(thread).Requests); got != 2 {
+		t.Errorf("expected 2 requests before closing responses. got: %d", got)
+	}
+	CloseResponses(thread)
+
+	sum := AuditSummaryFor(thread)
+	if len(sum.Requests) != 5 {
+		t.Fatalf("expected 5 requests. got: %d", len(sum.Requests))
+	}
+	expectURLs := []string{ts.URL + "/a", closed.URL, ts.URL + "/b", ts.URL + "/missing"}
+	if len(sum.URLs) != len(expectURLs) {
+		t.Errorf("expected urls %v. got: %v", expectURLs, sum.URLs)
+	}
+	for _, u := range expectURLs {
+		found := false
+		for _, got := range sum.URLs {
+			found = found || got == u
+		}
+		if !found {
+			t.Errorf("expected url %s in summary", u)
+		}
+	}
+	if sum.Errors != 1 {
+		t.Errorf("expected 1 error. got: %d", sum.Errors)
+	}
+	if sum.RequestBytes != 5 {
+		t.Errorf("expected 5 request bytes. got: %d", sum.RequestBytes)
+	}
+	if sum.ResponseBytes != 100 {
+		t.Errorf("expected 100 response bytes. got: %d", sum.ResponseBytes)
+	}
+
+	first := sum.Requests[0]
+	if first.Method != "GET" || first.URL != ts.URL+"/a" || first.StatusCode != 200 || first.ResponseBytes != 100 || first.Duration <= 0 {
+		t.Errorf("unexpected first record: %+v", first)
+	}
+
+	// closing responses again doesn't observe requests twice
+	CloseResponses(thread)
+	if got := len(AuditSummaryFor(thread).Requests); got != 5 {
+		t.Errorf("expected 5 requests. got: %d", got)
+	}
+	if sum := AuditSummaryFor(&starlark.Thread{}); len(sum.Requests) != 0 {
+		t.Errorf("expected empty summary for unobserved thread")
+	}
+}
+
+func TestAuditLogRoundTrips(t *testing.T) {
+	var (
+		lk       sync.Mutex
+		attempts int
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			fmt.Fprint(w, `{"access_token":"abc","token_type":"bearer","expires_in":3600}`)
+		case "/flaky":
+			lk.Lock()
+			attempts++
+			n := attempts
+			lk.Unlock()
+			if n == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			fmt.Fprint(w, "ok")
+		case "/redirect":
+			http.Redirect(w, r, "/ok", http.StatusFound)
+		case "/denied":
+			http.Redirect(w, r, "http://denied.example/", http.StatusFound)
+		default:
+			fmt.Fprint(w, "ok")
+		}
+	}))
+	defer ts.Close()
+
+	prevGuard, prevObserver := Guard, Observer
+	defer func() { Guard, Observer = prevGuard, prevObserver }()
+	Guard = DenyHosts("denied.example")
+	Observer = AuditLog{}
+
+	thread := &starlark.Thread{Load: testdata.NewLoader(LoadModule, ModuleName)}
+	starlarktest.SetReporter(thread, t)
+	script := fmt.Sprintf(`load('http.star', 'http')
+load('assert.star', 'assert')
+creds = http.OAuth2ClientCredentials(%[1]q + "/token", "client", "secret")
+assert.eq(http.get(%[1]q + "/ok", auth=creds).body(), "ok")
+assert.eq(http.get(%[1]q + "/flaky", retries=1, backoff=0).body(), "ok")
+assert.eq(http.get(%[1]q + "/redirect").body(), "ok")
+assert.fails(lambda: http.get(%[1]q + "/denied"), "denied.example")
+`, ts.URL)
+	if _, err := starlark.ExecFile(thread, "audit.star", script, nil); err != nil {
+		t.Fatal(err)
+	}
+	CloseResponses(thread)
+
+	type record struct {
+		url    string
+		status int
+		err    bool
+	}
+	expect := []record{
+		{ts.URL + "/token", 200, false},
+		{ts.URL + "/ok", 200, false},
+		{ts.URL + "/flaky", 503, false},
+		{ts.URL + "/flaky", 200, false},
+		{ts.URL + "/redirect", 302, false},
+		{ts.URL + "/ok", 200, false},
+		// the denied hop is observed before the client closes the redirect
+		{"http://denied.example/", 0, true},
+		{ts.URL + "/denied", 302, false},
+	}
+	sum := AuditSummaryFor(thread)
+	if len(sum.Requests) != len(expect) {
+		t.Fatalf("expected %d requests. got: %d\n%+v", len(expect), len(sum.Requests), sum.Requests)
+	}
+	for i, e := range expect {
+		got := sum.Requests[i]
+		if got.URL != e.url || got.StatusCode != e.status || (got.Err != nil) != e.err {
+			t.Errorf("request %d: expected %+v. got: %+v", i, e, got)
+		}
+	}
+	if tok := sum.Requests[0]; tok.Method != "POST" || tok.RequestBytes == 0 || tok.ResponseBytes == 0 {
+		t.Errorf("expected token request to be observed with its body sizes. got: %+v", tok)
+	}
+}
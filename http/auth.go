@@ -0,0 +1,219 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"go.starlark.net/starlark"
+)
+
+// authorizer is a starlark value that adds credentials to requests
+type authorizer interface {
+	starlark.Value
+	authorize(thread *starlark.Thread, m *Module, req *http.Request) error
+}
+
+// setRequestAuth applies an auth argument to a request. auth can be a
+// (username, password) tuple for basic auth, or an authorizer value
+func (m *Module) setRequestAuth(thread *starlark.Thread, req *http.Request, auth starlark.Value) error {
+	switch a := auth.(type) {
+	case nil, starlark.NoneType:
+		return nil
+	case starlark.Tuple:
+		return setAuth(req, a)
+	case authorizer:
+		return a.authorize(thread, m, req)
+	}
+	return fmt.Errorf("expected auth to be a (username,password) tuple, BearerAuth or OAuth2ClientCredentials. got: '%s'", auth.Type())
+}
+
+// checkAuth validates an auth argument without applying it
+func checkAuth(auth starlark.Value) error {
+	switch a := auth.(type) {
+	case nil, starlark.NoneType, authorizer:
+		return nil
+	case starlark.Tuple:
+		if len(a) == 0 || len(a) == 2 {
+			return nil
+		}
+		return fmt.Errorf("expected two values for auth params tuple")
+	}
+	return fmt.Errorf("expected auth to be a (username,password) tuple, BearerAuth or OAuth2ClientCredentials. got: '%s'", auth.Type())
+}
+
+// isEmptyAuth reports whether an auth argument provides no credentials
+func isEmptyAuth(auth starlark.Value) bool {
+	if auth == nil || auth == starlark.None {
+		return true
+	}
+	tup, ok := auth.(starlark.Tuple)
+	return ok && len(tup) == 0
+}
+
+// bearerAuth authorizes requests with a static bearer token
+type bearerAuth string
+
+var _ authorizer = bearerAuth("")
+
+// newBearerAuth creates bearer token auth: BearerAuth(token)
+func newBearerAuth(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var token string
+	if err := starlark.UnpackArgs("BearerAuth", args, kwargs, "token", &token); err != nil {
+		return nil, err
+	}
+	return bearerAuth(token), nil
+}
+
+// String implements the starlark.Value interface. tokens are never printed
+func (b bearerAuth) String() string { return "BearerAuth(...)" }
+
+// Type implements the starlark.Value interface
+func (b bearerAuth) Type() string { return "BearerAuth" }
+
+// Freeze implements the starlark.Value interface, bearerAuth is immutable
+func (b bearerAuth) Freeze() {}
+
+// Truth implements the starlark.Value interface
+func (b bearerAuth) Truth() starlark.Bool { return b != "" }
+
+// Hash implements the starlark.Value interface
+func (b bearerAuth) Hash() (uint32, error) { return starlark.String(b).Hash() }
+
+func (b bearerAuth) authorize(thread *starlark.Thread, m *Module, req *http.Request) error {
+	req.Header.Set("Authorization", "Bearer "+string(b))
+	return nil
+}
+
+// oauth2ClientCredentials authorizes requests with access tokens fetched
+// using the OAuth2 client credentials grant. Tokens are cached and reused
+// until they expire
+type oauth2ClientCredentials struct {
+	tokenURL     string
+	clientID     string
+	clientSecret string
+	scopes       []string
+
+	lk      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+var _ authorizer = (*oauth2ClientCredentials)(nil)
+
+// expiryDelta refreshes tokens slightly before they expire, so tokens don't
+// expire in flight
+const expiryDelta = 10 * time.Second
+
+// newOAuth2ClientCredentials creates client credentials auth:
+// OAuth2ClientCredentials(token_url, client_id, client_secret, scopes=[])
+func newOAuth2ClientCredentials(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var (
+		a      = &oauth2ClientCredentials{}
+		scopes *starlark.List
+	)
+	if err := starlark.UnpackArgs("OAuth2ClientCredentials", args, kwargs, "token_url", &a.tokenURL, "client_id", &a.clientID, "client_secret", &a.clientSecret, "scopes?", &scopes); err != nil {
+		return nil, err
+	}
+	if scopes != nil {
+		for i := 0; i < scopes.Len(); i++ {
+			s, ok := starlark.AsString(scopes.Index(i))
+			if !ok {
+				return nil, fmt.Errorf("OAuth2ClientCredentials: expected scopes to be strings. got: '%s'", scopes.Index(i).Type())
+			}
+			a.scopes = append(a.scopes, s)
+		}
+	}
+	return a, nil
+}
+
+// String implements the starlark.Value interface. secrets are never printed
+func (a *oauth2ClientCredentials) String() string {
+	return fmt.Sprintf("OAuth2ClientCredentials(token_url=%q, client_id=%q)", a.tokenURL, a.clientID)
+}
+
+// Type implements the starlark.Value interface
+func (a *oauth2ClientCredentials) Type() string { return "OAuth2ClientCredentials" }
+
+// Freeze implements the starlark.Value interface. the token cache remains
+// mutable, it isn't visible to starlark
+func (a *oauth2ClientCredentials) Freeze() {}
+
+// Truth implements the starlark.Value interface
+func (a *oauth2ClientCredentials) Truth() starlark.Bool { return starlark.True }
+
+// Hash implements the starlark.Value interface
+func (a *oauth2ClientCredentials) Hash() (uint32, error) {
+	return 0, fmt.Errorf("unhashable type: OAuth2ClientCredentials")
+}
+
+func (a *oauth2ClientCredentials) authorize(thread *starlark.Thread, m *Module, req *http.Request) error {
+	a.lk.Lock()
+	defer a.lk.Unlock()
+
+	if a.token == "" || (!a.expires.IsZero() && time.Now().After(a.expires.Add(-expiryDelta))) {
+		if err := a.fetchToken(thread, m); err != nil {
+			return err
+		}
+	}
+	req.Header.Set("Authorization", "Bearer "+a.token)
+	return nil
+}
+
+// fetchToken requests a new access token. token requests are checked by the
+// module's RequestGuard. callers must hold the lock
+func (a *oauth2ClientCredentials) fetchToken(thread *starlark.Thread, m *Module) error {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(a.scopes) > 0 {
+		form.Set("scope", strings.Join(a.scopes, " "))
+	}
+	req, err := http.NewRequest("POST", a.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	if m.rg != nil {
+		if req, err = m.rg.Allowed(thread, req); err != nil {
+			return err
+		}
+	}
+	req.Header.Set("Content-Type", formEncodingURL)
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(a.clientID), url.QueryEscape(a.clientSecret))
+	req = req.WithContext(withState(contextFor(thread), stateFor(thread)))
+
+	res, err := sendWithTimeout(m.cli, req, m.timeout)
+	if err != nil {
+		return fmt.Errorf("oauth2: requesting token: %s", err)
+	}
+	defer res.Body.Close()
+	body, err := ioutil.ReadAll(limitBody(res.Body, m.maxBodySize))
+	if err != nil {
+		return fmt.Errorf("oauth2: reading token response: %s", err)
+	}
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("oauth2: token request failed: %s: %s", res.Status, body)
+	}
+
+	tok := struct {
+		AccessToken string      `json:"access_token"`
+		ExpiresIn   json.Number `json:"expires_in"`
+	}{}
+	if err := json.Unmarshal(body, &tok); err != nil {
+		return fmt.Errorf("oauth2: parsing token response: %s", err)
+	}
+	if tok.AccessToken == "" {
+		return fmt.Errorf("oauth2: token response is missing access_token")
+	}
+
+	a.token = tok.AccessToken
+	a.expires = time.Time{}
+	if secs, err := tok.ExpiresIn.Int64(); err == nil && secs > 0 {
+		a.expires = time.Now().Add(time.Duration(secs) * time.Second)
+	}
+	return nil
+}
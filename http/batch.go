@@ -0,0 +1,127 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+)
+
+// defaultConcurrency is the number of requests get_all performs at once when
+// scripts don't specify a concurrency
+const defaultConcurrency = 4
+
+// getAll generates a starlark builtin that performs GET requests for a list of
+// urls concurrently: get_all(urls, concurrency=4, **kwargs). Keyword arguments
+// other than concurrency apply to every request. Results are returned in the
+// order of urls, and a failed request doesn't stop other requests. A non-nil
+// session performs requests with the session's state
+func (m *Module) getAll(s *Session) func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	return func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("get_all: expected 1 positional argument (urls), got %d", len(args))
+		}
+		iterable, ok := args[0].(starlark.Iterable)
+		if !ok {
+			return nil, fmt.Errorf("get_all: expected urls to be a list. got: '%s'", args[0].Type())
+		}
+
+		concurrency := defaultConcurrency
+		reqKwargs := make([]starlark.Tuple, 0, len(kwargs))
+		for _, kw := range kwargs {
+			if name, _ := starlark.AsString(kw[0]); name == "concurrency" {
+				n, err := starlark.AsInt32(kw[1])
+				if err != nil || n < 1 {
+					return nil, fmt.Errorf("get_all: concurrency must be a positive integer. got: %s", kw[1])
+				}
+				concurrency = n
+				continue
+			}
+			reqKwargs = append(reqKwargs, kw)
+		}
+		if m.maxConcurrency > 0 && concurrency > m.maxConcurrency {
+			concurrency = m.maxConcurrency
+		}
+
+		cli := m.cli
+		if s != nil {
+			cli = s.cli
+		}
+
+		var urls []starlark.Value
+		iter := iterable.Iterate()
+		var x starlark.Value
+		for iter.Next(&x) {
+			urls = append(urls, x)
+		}
+		iter.Done()
+
+		// requests are constructed on the calling thread, which runs guards and
+		// auth sequentially. only sending requests happens concurrently.
+		// thread state is resolved up front, as thread locals aren't safe to use
+		// from the goroutines sending requests, and observations are deferred
+		// until every request is done
+		st := stateFor(thread)
+		ctx := withState(contextFor(thread), st)
+		type pending struct {
+			req  *http.Request
+			opts reqOpts
+		}
+		var (
+			reqs    = make([]pending, len(urls))
+			results = make([]starlark.Value, len(urls))
+			errs    = make([]error, len(urls))
+		)
+		for i, u := range urls {
+			reqs[i].req, reqs[i].opts, errs[i] = m.newRequest(thread, "get", starlark.Tuple{u}, reqKwargs, s)
+			if errs[i] == nil {
+				reqs[i].req = reqs[i].req.WithContext(ctx)
+			}
+		}
+
+		var (
+			wg  sync.WaitGroup
+			sem = make(chan struct{}, concurrency)
+		)
+		st.deferObserve()
+		for i := range reqs {
+			if errs[i] != nil {
+				continue
+			}
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(i int) {
+				defer func() {
+					<-sem
+					wg.Done()
+				}()
+				results[i], errs[i] = m.do(thread, cli, reqs[i].req, reqs[i].opts)
+			}(i)
+		}
+		wg.Wait()
+		for _, rec := range st.flushObserved() {
+			m.observer.Observe(thread, rec)
+		}
+
+		list := make([]starlark.Value, len(urls))
+		for i, u := range urls {
+			var (
+				res    starlark.Value = starlark.None
+				errstr starlark.Value = starlark.None
+			)
+			if errs[i] != nil {
+				errstr = starlark.String(errs[i].Error())
+			} else {
+				res = results[i]
+			}
+			list[i] = starlarkstruct.FromStringDict(starlark.String("BatchResult"), starlark.StringDict{
+				"url":      u,
+				"response": res,
+				"error":    errstr,
+			})
+		}
+		return starlark.NewList(list), nil
+	}
+}
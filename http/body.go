@@ -0,0 +1,176 @@
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+
+	"go.starlark.net/starlark"
+)
+
+// ErrBodyTooLarge is returned when reading a response body exceeds the
+// module's maximum body size
+type ErrBodyTooLarge struct {
+	Limit int64
+}
+
+// Error implements the error interface
+func (e ErrBodyTooLarge) Error() string {
+	return fmt.Sprintf("response body exceeds maximum size of %d bytes", e.Limit)
+}
+
+// limitBody wraps a response body, failing reads once more than limit bytes
+// have been read. limit values less than one disable the limit
+func limitBody(body io.ReadCloser, limit int64) io.ReadCloser {
+	if limit <= 0 {
+		return body
+	}
+	return &limitedBody{ReadCloser: body, remaining: limit, limit: limit}
+}
+
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+	limit     int64
+}
+
+// Read implements the io.Reader interface
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.remaining < 0 {
+		return 0, ErrBodyTooLarge{Limit: b.limit}
+	}
+	// read one byte past the limit to detect bodies that exceed it
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.remaining -= int64(n)
+	if b.remaining < 0 {
+		return n + int(b.remaining), ErrBodyTooLarge{Limit: b.limit}
+	}
+	return n, err
+}
+
+// bodyIterator is a starlark iterable that reads a response body
+// incrementally, either line-by-line or in fixed-size chunks. A body can
+// only be consumed once. Iteration stops at the first read error, which
+// scripts can check with the error attribute
+type bodyIterator struct {
+	typ string
+	rdr *bufio.Reader
+	// chunkSize is the number of bytes per chunk. zero iterates lines
+	chunkSize int
+	// buf is reused to read each chunk
+	buf []byte
+	err error
+}
+
+// maxChunkSize is the largest chunk size iter_chunks accepts, chunks are
+// read into a buffer of the requested size
+const maxChunkSize = 1 << 20
+
+var (
+	_ starlark.Value    = (*bodyIterator)(nil)
+	_ starlark.Iterable = (*bodyIterator)(nil)
+	_ starlark.HasAttrs = (*bodyIterator)(nil)
+)
+
+// String implements the starlark.Value interface
+func (it *bodyIterator) String() string { return fmt.Sprintf("<%s>", it.typ) }
+
+// Type implements the starlark.Value interface
+func (it *bodyIterator) Type() string { return it.typ }
+
+// Freeze implements the starlark.Value interface
+func (it *bodyIterator) Freeze() {}
+
+// Truth implements the starlark.Value interface
+func (it *bodyIterator) Truth() starlark.Bool { return starlark.True }
+
+// Hash implements the starlark.Value interface
+func (it *bodyIterator) Hash() (uint32, error) {
+	return 0, fmt.Errorf("unhashable type: %s", it.typ)
+}
+
+// Attr implements the starlark.HasAttrs interface. error is the read error
+// that stopped iteration, or None
+func (it *bodyIterator) Attr(name string) (starlark.Value, error) {
+	if name != "error" {
+		return nil, nil
+	}
+	if it.err == nil {
+		return starlark.None, nil
+	}
+	return starlark.String(fmt.Sprintf("%s: %s", it.typ, it.err)), nil
+}
+
+// AttrNames implements the starlark.HasAttrs interface
+func (it *bodyIterator) AttrNames() []string { return []string{"error"} }
+
+// Iterate implements the starlark.Iterable interface
+func (it *bodyIterator) Iterate() starlark.Iterator { return it }
+
+// Next implements the starlark.Iterator interface. starlark iterators can't
+// return errors, so read errors stop iteration and are recorded on the
+// iterator
+func (it *bodyIterator) Next(p *starlark.Value) bool {
+	if it.err != nil {
+		return false
+	}
+	var (
+		data []byte
+		err  error
+	)
+	if it.chunkSize > 0 {
+		if it.buf == nil {
+			it.buf = make([]byte, it.chunkSize)
+		}
+		var n int
+		n, err = io.ReadFull(it.rdr, it.buf)
+		data = it.buf[:n]
+		if err == io.ErrUnexpectedEOF {
+			err = io.EOF
+		}
+		if err == nil || err == io.EOF {
+			*p = starlark.Bytes(data)
+			return len(data) > 0
+		}
+	} else {
+		data, err = it.rdr.ReadBytes('\n')
+		if err == nil || (err == io.EOF && len(data) > 0) {
+			*p = starlark.String(bytes.TrimSuffix(bytes.TrimSuffix(data, []byte("\n")), []byte("\r")))
+			return true
+		}
+	}
+
+	if err != io.EOF {
+		it.err = err
+	}
+	return false
+}
+
+// Done implements the starlark.Iterator interface
+func (it *bodyIterator) Done() {}
+
+// IterLines returns an iterable of response body lines with line endings
+// removed
+func (r *Response) IterLines(_ *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if err := starlark.UnpackArgs("iter_lines", args, kwargs); err != nil {
+		return nil, err
+	}
+	return &bodyIterator{typ: "iter_lines", rdr: bufio.NewReader(r.Body)}, nil
+}
+
+// IterChunks returns an iterable of response body bytes in chunks of up to
+// size bytes
+func (r *Response) IterChunks(_ *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	size := 1024
+	if err := starlark.UnpackArgs("iter_chunks", args, kwargs, "size?", &size); err != nil {
+		return nil, err
+	}
+	if size < 1 || size > maxChunkSize {
+		return nil, fmt.Errorf("iter_chunks: size must be a positive integer no larger than %d. got: %d", maxChunkSize, size)
+	}
+	return &bodyIterator{typ: "iter_chunks", rdr: bufio.NewReader(r.Body), chunkSize: size}, nil
+}
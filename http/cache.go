@@ -0,0 +1,418 @@
+package http
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ResponseCache stores encoded responses for a module. Set the package-level
+// Cache before calling LoadModule to cache responses of GET and HEAD requests.
+// Cached responses are served while they're fresh according to their
+// Cache-Control or Expires headers, and are otherwise revalidated with
+// conditional requests using their ETag and Last-Modified headers. A cache
+// may be shared by many threads, so responses marked private and responses to
+// requests with Authorization or Cookie headers aren't stored, unless they're
+// marked public. MemoryCache and DiskCache implement ResponseCache
+type ResponseCache interface {
+	// Get returns the data stored for key, reporting whether key was found
+	Get(key string) ([]byte, bool)
+	// Set stores data for key, replacing existing data
+	Set(key string, data []byte) error
+}
+
+// MemoryCache is a ResponseCache that keeps responses in memory
+type MemoryCache struct {
+	lk      sync.Mutex
+	entries map[string][]byte
+}
+
+var _ ResponseCache = (*MemoryCache)(nil)
+
+// NewMemoryCache creates an empty in-memory response cache
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{entries: map[string][]byte{}}
+}
+
+// Get implements the ResponseCache interface
+func (c *MemoryCache) Get(key string) ([]byte, bool) {
+	c.lk.Lock()
+	defer c.lk.Unlock()
+	data, ok := c.entries[key]
+	return data, ok
+}
+
+// Set implements the ResponseCache interface
+func (c *MemoryCache) Set(key string, data []byte) error {
+	c.lk.Lock()
+	defer c.lk.Unlock()
+	c.entries[key] = data
+	return nil
+}
+
+// DiskCache is a ResponseCache that stores each response as a file in a
+// directory, persisting responses across runs
+type DiskCache struct {
+	Dir string
+}
+
+var _ ResponseCache = (*DiskCache)(nil)
+
+// NewDiskCache creates a response cache in dir, creating the directory if it
+// doesn't exist
+func NewDiskCache(dir string) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &DiskCache{Dir: dir}, nil
+}
+
+// Get implements the ResponseCache interface
+func (c *DiskCache) Get(key string) ([]byte, bool) {
+	data, err := ioutil.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+// Set implements the ResponseCache interface. files are written atomically,
+// so concurrent readers never see a partial response
+func (c *DiskCache) Set(key string, data []byte) error {
+	f, err := ioutil.TempFile(c.Dir, "tmp-")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), c.path(key))
+}
+
+func (c *DiskCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.Dir, hex.EncodeToString(sum[:]))
+}
+
+// cacheEntry is the encoded form of a cached response
+type cacheEntry struct {
+	StatusCode int         `json:"statusCode"`
+	Status     string      `json:"status"`
+	Header     http.Header `json:"header"`
+	Body       []byte      `json:"body"`
+	// Vary holds the values of request headers named by the response Vary
+	// header. requests with different values don't match the entry
+	Vary   map[string]string `json:"vary,omitempty"`
+	Stored time.Time         `json:"stored"`
+	// Variants names the request headers responses vary on. entries with
+	// variants are stored under a request's cacheKey in place of a response,
+	// and each variant is stored under its variantKey
+	Variants []string `json:"variants,omitempty"`
+}
+
+// cacheKey identifies the cached response for a request
+func cacheKey(req *http.Request) string {
+	return req.Method + " " + req.URL.String()
+}
+
+// variantKey identifies the cached response for a request that matches the
+// values of the named request headers
+func variantKey(req *http.Request, names []string) string {
+	var sb strings.Builder
+	sb.WriteString(cacheKey(req))
+	for _, name := range names {
+		sb.WriteString("\n" + name + ": " + req.Header.Get(name))
+	}
+	return sb.String()
+}
+
+// key returns the key an entry for req is stored under
+func (e *cacheEntry) key(req *http.Request) string {
+	if len(e.Vary) == 0 {
+		return cacheKey(req)
+	}
+	names := make([]string, 0, len(e.Vary))
+	for name := range e.Vary {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return variantKey(req, names)
+}
+
+// cacheable reports whether responses to a request can be cached
+func cacheable(req *http.Request) bool {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return false
+	}
+	_, noStore := cacheControl(req.Header)["no-store"]
+	return !noStore
+}
+
+// lookup returns the cached entry for a request, or nil if the cache has no
+// matching entry. requests with credentials, including cookies the client
+// will add, only match public entries
+func lookup(cache ResponseCache, req *http.Request, cli *http.Client) *cacheEntry {
+	e := get(cache, cacheKey(req))
+	if e != nil && len(e.Variants) > 0 {
+		e = get(cache, variantKey(req, e.Variants))
+	}
+	if e == nil {
+		return nil
+	}
+	if _, public := cacheControl(e.Header)["public"]; !public {
+		if hasCredentials(req) || (cli.Jar != nil && len(cli.Jar.Cookies(req.URL)) > 0) {
+			return nil
+		}
+	}
+	for name, val := range e.Vary {
+		if req.Header.Get(name) != val {
+			return nil
+		}
+	}
+	return e
+}
+
+// get decodes the entry stored under key, returning nil if there is none
+func get(cache ResponseCache, key string) *cacheEntry {
+	data, ok := cache.Get(key)
+	if !ok {
+		return nil
+	}
+	e := &cacheEntry{}
+	if err := json.Unmarshal(data, e); err != nil {
+		return nil
+	}
+	return e
+}
+
+// cacheResponse stores a response in the module cache, returning the response
+// scripts should receive and whether it came from the cache. A 304 Not
+// Modified response to a revalidation request refreshes & returns the cached
+// entry. Stored response bodies are read in full
+func (m *Module) cacheResponse(req *http.Request, res *http.Response, cached *cacheEntry, revalidating bool) (*http.Response, bool, error) {
+	if revalidating && res.StatusCode == http.StatusNotModified {
+		res.Body.Close()
+		cached.update(res)
+		data, err := json.Marshal(cached)
+		if err != nil {
+			return nil, false, err
+		}
+		if err := m.cache.Set(cached.key(req), data); err != nil {
+			return nil, false, err
+		}
+		return cached.response(res.Request), true, nil
+	}
+	if !storable(req, res) {
+		return res, false, nil
+	}
+
+	body, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, false, err
+	}
+	res.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return res, false, store(m.cache, req, res, body)
+}
+
+// store encodes a response in the cache
+func store(cache ResponseCache, req *http.Request, res *http.Response, body []byte) error {
+	e := &cacheEntry{
+		StatusCode: res.StatusCode,
+		Status:     res.Status,
+		Header:     res.Header,
+		Body:       body,
+		Stored:     time.Now(),
+	}
+	names := varyHeaders(res.Header)
+	if len(names) > 0 {
+		// store an index of the headers responses vary on, so each variant
+		// gets its own key instead of replacing other variants
+		sort.Strings(names)
+		index, err := json.Marshal(&cacheEntry{Variants: names})
+		if err != nil {
+			return err
+		}
+		if err := cache.Set(cacheKey(req), index); err != nil {
+			return err
+		}
+		e.Vary = map[string]string{}
+		for _, name := range names {
+			e.Vary[name] = req.Header.Get(name)
+		}
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return cache.Set(e.key(req), data)
+}
+
+// storable reports whether a response can be reused. responses that can't be
+// revalidated are only stored when they specify a freshness lifetime. private
+// responses, and responses to requests with credentials that aren't marked
+// public, could leak to other users of the cache and aren't stored
+func storable(req *http.Request, res *http.Response) bool {
+	if res.StatusCode != http.StatusOK {
+		return false
+	}
+	cc := cacheControl(res.Header)
+	if _, ok := cc["no-store"]; ok {
+		return false
+	}
+	if _, ok := cc["private"]; ok {
+		return false
+	}
+	if _, public := cc["public"]; !public && (hasCredentials(req) || (res.Request != nil && hasCredentials(res.Request))) {
+		return false
+	}
+	for _, name := range varyHeaders(res.Header) {
+		if name == "*" {
+			return false
+		}
+	}
+	return res.Header.Get("ETag") != "" || res.Header.Get("Last-Modified") != "" || lifetime(res.Header) > 0
+}
+
+// hasCredentials reports whether a request carries authorization or cookies.
+// clients add cookies from their jar to the request they send, which is the
+// request of the response
+func hasCredentials(req *http.Request) bool {
+	return req.Header.Get("Authorization") != "" || req.Header.Get("Cookie") != ""
+}
+
+// fresh reports whether an entry can be used without revalidation
+func (e *cacheEntry) fresh(req *http.Request, now time.Time) bool {
+	if _, ok := cacheControl(req.Header)["no-cache"]; ok {
+		return false
+	}
+	if _, ok := cacheControl(e.Header)["no-cache"]; ok {
+		return false
+	}
+	return now.Before(e.Stored.Add(lifetime(e.Header)))
+}
+
+// revalidate adds conditional headers to a request using the entry's
+// validators, reporting whether any were added. validators set by scripts are
+// left as they are
+func (e *cacheEntry) revalidate(req *http.Request) bool {
+	if req.Header.Get("If-None-Match") != "" || req.Header.Get("If-Modified-Since") != "" {
+		return false
+	}
+	added := false
+	if etag := e.Header.Get("ETag"); etag != "" {
+		req.Header.Set("If-None-Match", etag)
+		added = true
+	}
+	if lm := e.Header.Get("Last-Modified"); lm != "" {
+		req.Header.Set("If-Modified-Since", lm)
+		added = true
+	}
+	return added
+}
+
+// update refreshes an entry with the headers of a 304 Not Modified response
+func (e *cacheEntry) update(res *http.Response) {
+	for name, vals := range res.Header {
+		switch name {
+		case "Content-Length", "Content-Encoding", "Transfer-Encoding", "Content-Type":
+			continue
+		}
+		e.Header[name] = vals
+	}
+	e.Stored = time.Now()
+}
+
+// response creates an http response for req from the entry
+func (e *cacheEntry) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        e.Status,
+		StatusCode:    e.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        cloneHeader(e.Header),
+		Body:          ioutil.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
+
+func cloneHeader(h http.Header) http.Header {
+	clone := make(http.Header, len(h))
+	for key, vals := range h {
+		clone[key] = append([]string(nil), vals...)
+	}
+	return clone
+}
+
+// lifetime returns how long a response is fresh for, preferring the
+// Cache-Control max-age directive over the Expires header
+func lifetime(h http.Header) time.Duration {
+	if age, ok := cacheControl(h)["max-age"]; ok {
+		secs, err := strconv.Atoi(age)
+		if err != nil || secs < 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+	if exp := h.Get("Expires"); exp != "" {
+		expires, err := http.ParseTime(exp)
+		if err != nil {
+			return 0
+		}
+		date, err := http.ParseTime(h.Get("Date"))
+		if err != nil {
+			date = time.Now()
+		}
+		return expires.Sub(date)
+	}
+	return 0
+}
+
+// cacheControl parses Cache-Control header directives
+func cacheControl(h http.Header) map[string]string {
+	cc := map[string]string{}
+	for _, part := range strings.Split(h.Get("Cache-Control"), ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		kv := strings.SplitN(part, "=", 2)
+		key := strings.ToLower(strings.TrimSpace(kv[0]))
+		if len(kv) == 2 {
+			cc[key] = strings.Trim(strings.TrimSpace(kv[1]), `"`)
+		} else {
+			cc[key] = ""
+		}
+	}
+	return cc
+}
+
+func varyHeaders(h http.Header) []string {
+	var names []string
+	for _, v := range h["Vary"] {
+		for _, name := range strings.Split(v, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+	return names
+}
@@ -0,0 +1,131 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/qri-io/starlib/testdata"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarktest"
+)
+
+// cacheServer counts requests to each path, serving responses with caching
+// headers
+func cacheServer() (*httptest.Server, func(path string) int) {
+	var (
+		lk     sync.Mutex
+		counts = map[string]int{}
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lk.Lock()
+		counts[r.URL.Path]++
+		n := counts[r.URL.Path]
+		lk.Unlock()
+
+		switch r.URL.Path {
+		case "/etag":
+			w.Header().Set("ETag", `"v1"`)
+			w.Header().Set("X-Request", fmt.Sprintf("%d", n))
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			fmt.Fprint(w, "etag body")
+		case "/last-modified":
+			w.Header().Set("Last-Modified", "Wed, 21 Oct 2015 07:28:00 GMT")
+			if r.Header.Get("If-Modified-Since") == "Wed, 21 Oct 2015 07:28:00 GMT" {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			fmt.Fprint(w, "last modified body")
+		case "/fresh":
+			w.Header().Set("Cache-Control", "max-age=60")
+			fmt.Fprintf(w, "fresh %d", n)
+		case "/vary":
+			w.Header().Set("Cache-Control", "max-age=60")
+			w.Header().Set("Vary", "Accept")
+			fmt.Fprintf(w, "%s %d", r.Header.Get("Accept"), n)
+		case "/auth":
+			w.Header().Set("Cache-Control", "max-age=60")
+			fmt.Fprintf(w, "%s%s %d", r.Header.Get("Authorization"), r.Header.Get("Cookie"), n)
+		case "/private":
+			w.Header().Set("Cache-Control", "private, max-age=60")
+			fmt.Fprintf(w, "private %d", n)
+		case "/public":
+			w.Header().Set("Cache-Control", "public, max-age=60")
+			fmt.Fprintf(w, "public %d", n)
+		case "/no-store":
+			w.Header().Set("Cache-Control", "no-store")
+			w.Header().Set("ETag", `"v1"`)
+			fmt.Fprintf(w, "no store %d", n)
+		}
+	}))
+	count := func(path string) int {
+		lk.Lock()
+		defer lk.Unlock()
+		return counts[path]
+	}
+	return ts, count
+}
+
+func TestResponseCache(t *testing.T) {
+	ts, count := cacheServer()
+	defer ts.Close()
+	starlark.Universe["test_server_url"] = starlark.String(ts.URL)
+
+	prev := Cache
+	Cache = NewMemoryCache()
+	defer func() { Cache = prev }()
+
+	thread := &starlark.Thread{Load: testdata.NewLoader(LoadModule, ModuleName)}
+	starlarktest.SetReporter(thread, t)
+	if _, err := starlark.ExecFile(thread, "testdata/cache.star", nil, nil); err != nil {
+		t.Error(err)
+	}
+
+	// the first request, a no-cache request and a POST request
+	if n := count("/fresh"); n != 3 {
+		t.Errorf("expected 3 requests for fresh responses. got: %d", n)
+	}
+	if n := count("/etag"); n != 2 {
+		t.Errorf("expected etag responses to be revalidated. got %d requests", n)
+	}
+}
+
+func TestDiskCache(t *testing.T) {
+	ts, count := cacheServer()
+	defer ts.Close()
+
+	c, err := NewDiskCache(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	prev := Cache
+	Cache = c
+	defer func() { Cache = prev }()
+
+	script := fmt.Sprintf(`load('http.star', 'http')
+load('assert.star', 'assert')
+res = http.get(%q)
+assert.eq(res.body(), "fresh 1")
+`, ts.URL+"/fresh")
+
+	// responses persist across modules
+	for i := 0; i < 2; i++ {
+		thread := &starlark.Thread{Load: testdata.NewLoader(LoadModule, ModuleName)}
+		starlarktest.SetReporter(thread, t)
+		if _, err := starlark.ExecFile(thread, "disk_cache.star", script, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := count("/fresh"); n != 1 {
+		t.Errorf("expected 1 request. got: %d", n)
+	}
+
+	if _, ok := c.Get("GET " + ts.URL + "/missing"); ok {
+		t.Error("expected missing key to not be found")
+	}
+}
@@ -0,0 +1,393 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v2"
+)
+
+// RecorderMode determines how a Recorder handles requests
+type RecorderMode int
+
+const (
+	// ModeReplayOrRecord replays recorded interactions that match a request,
+	// recording requests that don't match any interaction
+	ModeReplayOrRecord RecorderMode = iota
+	// ModeReplay only replays recorded interactions. Requests that don't match
+	// an interaction fail
+	ModeReplay
+	// ModeRecord performs all requests, replacing any recorded interactions
+	ModeRecord
+)
+
+// MatchRule is a set of request properties a recorded interaction must share
+// with a request to replay it
+type MatchRule int
+
+const (
+	// MatchMethod compares request methods
+	MatchMethod MatchRule = 1 << iota
+	// MatchURL compares full request urls, including query strings
+	MatchURL
+	// MatchBody compares request bodies
+	MatchBody
+	// MatchHeaders compares the request headers listed in Recorder.MatchHeaders
+	MatchHeaders
+)
+
+// DefaultMatch compares request methods & urls
+const DefaultMatch = MatchMethod | MatchURL
+
+// RedactedValue replaces redacted header values in saved cassettes
+const RedactedValue = "REDACTED"
+
+// Cassette is a list of recorded request/response pairs
+type Cassette struct {
+	Interactions []*Interaction `json:"interactions" yaml:"interactions"`
+}
+
+// Interaction is a recorded request/response pair
+type Interaction struct {
+	Request  RecordedRequest  `json:"request" yaml:"request"`
+	Response RecordedResponse `json:"response" yaml:"response"`
+	replayed bool
+}
+
+// RecordedRequest is the serializable form of an http request
+type RecordedRequest struct {
+	Method  string              `json:"method" yaml:"method"`
+	URL     string              `json:"url" yaml:"url"`
+	Headers map[string][]string `json:"headers,omitempty" yaml:"headers,omitempty"`
+	Body    RecordedBody        `json:"body,omitempty" yaml:"body,omitempty"`
+}
+
+// RecordedResponse is the serializable form of an http response
+type RecordedResponse struct {
+	StatusCode int                 `json:"status_code" yaml:"status_code"`
+	Headers    map[string][]string `json:"headers,omitempty" yaml:"headers,omitempty"`
+	Body       RecordedBody        `json:"body,omitempty" yaml:"body,omitempty"`
+}
+
+// RecordedBody is a recorded request or response body. Bodies are base64
+// encoded in JSON cassettes. YAML cassettes store UTF-8 bodies as strings,
+// and other bodies as base64 encoded !!binary values
+type RecordedBody []byte
+
+// MarshalYAML implements the yaml.Marshaler interface
+func (b RecordedBody) MarshalYAML() (interface{}, error) {
+	return string(b), nil
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface
+func (b *RecordedBody) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	*b = RecordedBody(s)
+	return nil
+}
+
+// Recorder is an http.RoundTripper that records requests & responses to a
+// cassette file, and replays them in place of performing requests. Install a
+// recorder as the transport of the package-level Client before calling
+// LoadModule, and call Save once scripts have finished executing. Recorder is
+// safe for concurrent use
+type Recorder struct {
+	// Path is the cassette file location. Paths ending in ".json" are encoded
+	// as JSON, all others as YAML
+	Path string
+	// Mode determines when requests are replayed or recorded
+	Mode RecorderMode
+	// Transport performs requests that are recorded. defaults to
+	// http.DefaultTransport
+	Transport http.RoundTripper
+	// Match sets the properties a request must share with a recorded
+	// interaction to be replayed
+	Match MatchRule
+	// MatchHeaders lists headers compared when Match includes MatchHeaders
+	MatchHeaders []string
+	// Redact lists request headers with values replaced by RedactedValue
+	// when recorded. Redacted headers are never compared when matching
+	Redact []string
+	// RedactResponse lists response headers with values replaced by
+	// RedactedValue when recorded. Replayed responses have the redacted values
+	RedactResponse []string
+
+	lk       sync.Mutex
+	cassette *Cassette
+	dirty    bool
+}
+
+// NewRecorder creates a recorder, loading the cassette at path if one
+// exists. The recorder matches on method and url, and redacts Authorization
+// and Cookie request headers, and Set-Cookie response headers
+func NewRecorder(path string, mode RecorderMode) (*Recorder, error) {
+	r := &Recorder{
+		Path:           path,
+		Mode:           mode,
+		Match:          DefaultMatch,
+		Redact:         []string{"Authorization", "Proxy-Authorization", "Cookie"},
+		RedactResponse: []string{"Set-Cookie", "Set-Cookie2"},
+		cassette:       &Cassette{},
+	}
+
+	if mode == ModeRecord {
+		return r, nil
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) && mode == ModeReplayOrRecord {
+		return r, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if r.isJSON() {
+		err = json.Unmarshal(data, r.cassette)
+	} else {
+		err = yaml.Unmarshal(data, r.cassette)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading cassette %s: %s", path, err)
+	}
+	return r, nil
+}
+
+// RoundTrip implements the http.RoundTripper interface
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	rec, out, err := r.recordRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	r.lk.Lock()
+	if r.cassette == nil {
+		r.cassette = &Cassette{}
+	}
+	if r.Mode != ModeRecord {
+		if in := r.find(rec); in != nil {
+			in.replayed = true
+			r.lk.Unlock()
+			closeBody(out)
+			return in.Response.response(req), nil
+		}
+	}
+	r.lk.Unlock()
+
+	if r.Mode == ModeReplay {
+		closeBody(out)
+		return nil, fmt.Errorf("cassette %s: no recorded interaction matches request %s %s", r.Path, req.Method, req.URL)
+	}
+
+	transport := r.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	res, err := transport.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+	body, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	r.lk.Lock()
+	defer r.lk.Unlock()
+	r.cassette.Interactions = append(r.cassette.Interactions, &Interaction{
+		Request: rec,
+		Response: RecordedResponse{
+			StatusCode: res.StatusCode,
+			Headers:    redactHeaders(res.Header, r.RedactResponse),
+			Body:       body,
+		},
+		replayed: true,
+	})
+	r.dirty = true
+	return res, nil
+}
+
+// Save writes the cassette to Path if any interactions were recorded
+func (r *Recorder) Save() error {
+	r.lk.Lock()
+	defer r.lk.Unlock()
+	if !r.dirty {
+		return nil
+	}
+
+	var (
+		data []byte
+		err  error
+	)
+	if r.isJSON() {
+		data, err = json.MarshalIndent(r.cassette, "", "  ")
+	} else {
+		data, err = yaml.Marshal(r.cassette)
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.Path), 0755); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(r.Path, data, 0644); err != nil {
+		return err
+	}
+	r.dirty = false
+	return nil
+}
+
+func (r *Recorder) isJSON() bool {
+	return strings.HasSuffix(strings.ToLower(r.Path), ".json")
+}
+
+// recordRequest records a request. RoundTrippers must not modify requests, so
+// bodies are read from GetBody when it's set, otherwise from a copy of the
+// request that holds the read body. the returned request is the one to send
+func (r *Recorder) recordRequest(req *http.Request) (RecordedRequest, *http.Request, error) {
+	rec := RecordedRequest{
+		Method:  req.Method,
+		URL:     req.URL.String(),
+		Headers: redactHeaders(req.Header, r.Redact),
+	}
+	if req.Body == nil || req.Body == http.NoBody {
+		return rec, req, nil
+	}
+
+	if req.GetBody != nil {
+		rc, err := req.GetBody()
+		if err != nil {
+			closeBody(req)
+			return rec, nil, err
+		}
+		body, err := ioutil.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			closeBody(req)
+			return rec, nil, err
+		}
+		rec.Body = body
+		return rec, req, nil
+	}
+
+	body, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return rec, nil, err
+	}
+	out := new(http.Request)
+	*out = *req
+	out.Body = ioutil.NopCloser(bytes.NewReader(body))
+	rec.Body = body
+	return rec, out, nil
+}
+
+// closeBody closes the body of a request that won't be sent
+func closeBody(req *http.Request) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+}
+
+// redactHeaders copies headers, replacing the values of headers listed in
+// redact with RedactedValue
+func redactHeaders(header http.Header, redact []string) map[string][]string {
+	headers := map[string][]string{}
+	for key, vals := range header {
+		headers[key] = append([]string(nil), vals...)
+	}
+	for _, key := range redact {
+		key = http.CanonicalHeaderKey(key)
+		if _, ok := headers[key]; ok {
+			headers[key] = []string{RedactedValue}
+		}
+	}
+	return headers
+}
+
+// find returns the first matching interaction that hasn't been replayed,
+// falling back to the first matching interaction. callers must hold the lock
+func (r *Recorder) find(req RecordedRequest) *Interaction {
+	var match *Interaction
+	for _, in := range r.cassette.Interactions {
+		if !r.matches(in.Request, req) {
+			continue
+		}
+		if !in.replayed {
+			return in
+		}
+		if match == nil {
+			match = in
+		}
+	}
+	return match
+}
+
+// matches compares a recorded request to a request according to the
+// recorder's match rules
+func (r *Recorder) matches(recorded, req RecordedRequest) bool {
+	match := r.Match
+	if match == 0 {
+		match = DefaultMatch
+	}
+
+	if match&MatchMethod != 0 && recorded.Method != req.Method {
+		return false
+	}
+	if match&MatchURL != 0 && recorded.URL != req.URL {
+		return false
+	}
+	if match&MatchBody != 0 && !bytes.Equal(recorded.Body, req.Body) {
+		return false
+	}
+	if match&MatchHeaders != 0 {
+		for _, key := range r.MatchHeaders {
+			key = http.CanonicalHeaderKey(key)
+			if r.redacted(key) {
+				continue
+			}
+			if strings.Join(recorded.Headers[key], ",") != strings.Join(req.Headers[key], ",") {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (r *Recorder) redacted(key string) bool {
+	for _, k := range r.Redact {
+		if http.CanonicalHeaderKey(k) == key {
+			return true
+		}
+	}
+	return false
+}
+
+// response creates an http response from a recorded response
+func (rr RecordedResponse) response(req *http.Request) *http.Response {
+	header := http.Header{}
+	for key, vals := range rr.Headers {
+		header[key] = append([]string(nil), vals...)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", rr.StatusCode, http.StatusText(rr.StatusCode)),
+		StatusCode:    rr.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(rr.Body)),
+		ContentLength: int64(len(rr.Body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,208 @@
+package http
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/qri-io/starlib/testdata"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarktest"
+)
+
+func TestRecorder(t *testing.T) {
+	dir, err := ioutil.TempDir("", "starlib_http_cassette")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	hits := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		switch r.URL.Path {
+		case "/hello":
+			w.Write([]byte(`{"hello":"world"}`))
+		case "/echo":
+			data, _ := ioutil.ReadAll(r.Body)
+			w.WriteHeader(http.StatusCreated)
+			w.Write(data)
+		}
+	}))
+	defer ts.Close()
+	starlark.Universe["test_server_url"] = starlark.String(ts.URL)
+
+	prev := Client
+	defer func() { Client = prev }()
+
+	exec := func(rec *Recorder) error {
+		Client = &http.Client{Transport: rec}
+		thread := &starlark.Thread{Load: testdata.NewLoader(LoadModule, ModuleName)}
+		starlarktest.SetReporter(thread, t)
+		_, err := starlark.ExecFile(thread, "testdata/cassette.star", nil, nil)
+		return err
+	}
+
+	for _, name := range []string{"cassette.yaml", "cassette.json"} {
+		hits = 0
+		path := filepath.Join(dir, name)
+
+		rec, err := NewRecorder(path, ModeReplayOrRecord)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := exec(rec); err != nil {
+			t.Fatal(err)
+		}
+		if err := rec.Save(); err != nil {
+			t.Fatal(err)
+		}
+		if hits != 2 {
+			t.Errorf("%s: expected recording to make 2 requests. got: %d", name, hits)
+		}
+
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(data), "secret") || !strings.Contains(string(data), RedactedValue) {
+			t.Errorf("%s: expected Authorization header to be redacted. got:\n%s", name, data)
+		}
+
+		// replaying from the saved cassette performs no requests
+		rec, err = NewRecorder(path, ModeReplay)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rec.Match = MatchMethod | MatchURL | MatchBody | MatchHeaders
+		rec.MatchHeaders = []string{"Authorization", "Content-Type"}
+		if err := exec(rec); err != nil {
+			t.Fatal(err)
+		}
+		if hits != 2 {
+			t.Errorf("%s: expected replay to make no requests. got: %d", name, hits-2)
+		}
+	}
+
+	rec, err := NewRecorder(filepath.Join(dir, "cassette.yaml"), ModeReplay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec.Match = DefaultMatch | MatchBody
+	req := httptest.NewRequest("POST", ts.URL+"/echo", strings.NewReader("pong"))
+	if _, err := rec.RoundTrip(req); err == nil || !strings.Contains(err.Error(), "no recorded interaction matches") {
+		t.Errorf("expected unmatched request to error. got: %v", err)
+	}
+
+	if _, err := NewRecorder(filepath.Join(dir, "missing.yaml"), ModeReplay); !os.IsNotExist(err) {
+		t.Errorf("expected replaying a missing cassette to error. got: %v", err)
+	}
+}
+
+func TestRecorderBinaryBody(t *testing.T) {
+	dir, err := ioutil.TempDir("", "starlib_http_cassette")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// a gzip header, which isn't valid UTF-8
+	body := []byte{0x1f, 0x8b, 0x08, 0x00, 0xff, 0xfe, 0x00, 0x01}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "secret"})
+		w.Write(body)
+	}))
+	defer ts.Close()
+
+	for _, name := range []string{"binary.yaml", "binary.json"} {
+		path := filepath.Join(dir, name)
+		rec, err := NewRecorder(path, ModeRecord)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res, err := rec.RoundTrip(httptest.NewRequest("GET", ts.URL+"/bin", nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+		if err := rec.Save(); err != nil {
+			t.Fatal(err)
+		}
+
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(data), "secret") {
+			t.Errorf("%s: expected Set-Cookie header to be redacted. got:\n%s", name, data)
+		}
+
+		if rec, err = NewRecorder(path, ModeReplay); err != nil {
+			t.Fatal(err)
+		}
+		if res, err = rec.RoundTrip(httptest.NewRequest("GET", ts.URL+"/bin", nil)); err != nil {
+			t.Fatal(err)
+		}
+		got, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, body) {
+			t.Errorf("%s: expected replayed body %x. got: %x", name, body, got)
+		}
+		if c := res.Header.Get("Set-Cookie"); c != RedactedValue {
+			t.Errorf("%s: expected replayed Set-Cookie to be %q. got: %q", name, RedactedValue, c)
+		}
+	}
+}
+
+func TestRecorderRequestBody(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		w.Write(data)
+	}))
+	defer ts.Close()
+
+	rec := &Recorder{Path: "unsaved.yaml", Mode: ModeRecord}
+	// readers wrapped in a struct don't get a GetBody func from NewRequest
+	bodies := map[string]io.Reader{
+		"get_body":    strings.NewReader("hello"),
+		"no_get_body": struct{ io.Reader }{strings.NewReader("hello")},
+	}
+	for name, r := range bodies {
+		req, err := http.NewRequest("POST", ts.URL+"/"+name, r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body := req.Body
+		res, err := rec.RoundTrip(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != "hello" {
+			t.Errorf("%s: expected server to receive body %q. got: %q", name, "hello", got)
+		}
+		if req.Body != body {
+			t.Errorf("%s: expected RoundTrip not to replace the request body", name)
+		}
+	}
+
+	if len(rec.cassette.Interactions) != 2 {
+		t.Fatalf("expected 2 interactions. got: %d", len(rec.cassette.Interactions))
+	}
+	for _, in := range rec.cassette.Interactions {
+		if string(in.Request.Body) != "hello" {
+			t.Errorf("%s: expected recorded body %q. got: %q", in.Request.URL, "hello", in.Request.Body)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package http
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html/charset"
+	"golang.org/x/text/encoding"
+)
+
+// decodeText converts data to a UTF-8 string. an explicit encoding name takes
+// precedence over the charset declared in contentType. When neither is
+// present the encoding is sniffed from data
+func decodeText(data []byte, contentType, name string) (string, error) {
+	var enc encoding.Encoding
+	if name != "" {
+		if enc, _ = charset.Lookup(name); enc == nil {
+			// accept common spellings like "latin-1" or "shift-jis"
+			if enc, _ = charset.Lookup(strings.NewReplacer("-", "", "_", "").Replace(name)); enc == nil {
+				return "", fmt.Errorf("unknown encoding: %q", name)
+			}
+		}
+	} else {
+		var (
+			detected string
+			certain  bool
+		)
+		enc, detected, certain = charset.DetermineEncoding(data, contentType)
+		// DetermineEncoding only samples the start of data, and falls back to
+		// windows-1252 when sampled bytes aren't conclusive
+		if !certain && detected == "windows-1252" && utf8.Valid(data) {
+			enc = encoding.Nop
+		}
+	}
+
+	if enc == encoding.Nop {
+		return string(data), nil
+	}
+	decoded, err := enc.NewDecoder().Bytes(data)
+	if err != nil {
+		return "", err
+	}
+	return string(decoded), nil
+}
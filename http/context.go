@@ -0,0 +1,268 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unsafe"
+
+	"go.starlark.net/starlark"
+)
+
+// localsLk guards the thread locals this package reads and writes. hosts may
+// cancel a thread or read its request count and audit summary while the
+// thread is executing, and thread locals aren't safe for concurrent use
+var localsLk sync.Mutex
+
+// keyContext is the thread-local key for a thread's request context
+const keyContext = "http.context"
+
+// threadContext pairs a context with the func that cancels it
+type threadContext struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// SetContext attaches a context to a starlark thread. All http requests made
+// by the thread use the context, and are aborted when it's cancelled. Call
+// SetContext before executing starlark code on the thread. Requests are also
+// aborted by thread.Cancel, with or without a context
+func SetContext(thread *starlark.Thread, ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	localsLk.Lock()
+	defer localsLk.Unlock()
+	thread.SetLocal(keyContext, &threadContext{ctx: ctx, cancel: cancel})
+}
+
+// CancelThread cancels a starlark thread, aborting any in-flight http requests
+// started by the thread. thread.Cancel aborts requests within
+// cancelPollInterval, CancelThread also cancels the context set with
+// SetContext, aborting requests immediately. CancelThread is safe to call
+// from any goroutine
+func CancelThread(thread *starlark.Thread, reason string) {
+	thread.Cancel(reason)
+	localsLk.Lock()
+	tc, ok := thread.Local(keyContext).(*threadContext)
+	localsLk.Unlock()
+	if ok {
+		tc.cancel()
+	}
+}
+
+// threadState is the state of a thread that requests share. thread locals
+// aren't safe for concurrent use, so state is resolved on the thread's
+// goroutine with stateFor and carried in the request context, where requests
+// made concurrently by get_all can use it
+type threadState struct {
+	thread *starlark.Thread
+	count  *requestCounter
+	open   *openBodies
+
+	lk sync.Mutex
+	// deferred queues observed records while deferring is true, see
+	// deferObserve
+	deferring bool
+	deferred  []RequestRecord
+}
+
+// keyThreadState is the context key for the state of the thread that made a
+// request
+type keyThreadState struct{}
+
+// stateFor resolves the state of a thread, creating any state that doesn't
+// exist. must be called on the thread's goroutine
+func stateFor(thread *starlark.Thread) *threadState {
+	st := &threadState{thread: thread}
+	if thread != nil {
+		st.count = requestCounterFor(thread)
+		st.open = openBodiesFor(thread)
+	}
+	return st
+}
+
+// withState attaches thread state to a request context
+func withState(ctx context.Context, st *threadState) context.Context {
+	return context.WithValue(ctx, keyThreadState{}, st)
+}
+
+// stateFrom returns the thread state of a request context, or nil
+func stateFrom(ctx context.Context) *threadState {
+	st, _ := ctx.Value(keyThreadState{}).(*threadState)
+	return st
+}
+
+// deferObserve queues records observed with the state until flushObserved is
+// called, so observers are only called on the thread's goroutine
+func (st *threadState) deferObserve() {
+	st.lk.Lock()
+	defer st.lk.Unlock()
+	st.deferring = true
+}
+
+// flushObserved stops deferring observed records, returning records queued
+// since deferObserve
+func (st *threadState) flushObserved() []RequestRecord {
+	st.lk.Lock()
+	defer st.lk.Unlock()
+	recs := st.deferred
+	st.deferring = false
+	st.deferred = nil
+	return recs
+}
+
+// contextFor returns the request context for a thread
+func contextFor(thread *starlark.Thread) context.Context {
+	if thread != nil {
+		localsLk.Lock()
+		defer localsLk.Unlock()
+		if tc, ok := thread.Local(keyContext).(*threadContext); ok {
+			return tc.ctx
+		}
+	}
+	return context.Background()
+}
+
+// cancelPollInterval is how often in-flight requests check whether their
+// thread has been cancelled
+const cancelPollInterval = 50 * time.Millisecond
+
+// cancelReasonOffset is the offset of the field starlark.Thread uses to record
+// cancellation. starlark doesn't notify modules when a thread is cancelled or
+// export the field, so requests poll it to abort when a host calls
+// thread.Cancel. hasCancelReason is false if the field can't be found
+var cancelReasonOffset, hasCancelReason = func() (uintptr, bool) {
+	f, ok := reflect.TypeOf(starlark.Thread{}).FieldByName("cancelReason")
+	if !ok || f.Type != reflect.TypeOf((*string)(nil)) {
+		return 0, false
+	}
+	return f.Offset, true
+}()
+
+// threadCancelled reports whether thread.Cancel has been called on a thread
+func threadCancelled(thread *starlark.Thread) bool {
+	if thread == nil || !hasCancelReason {
+		return false
+	}
+	reason := (*unsafe.Pointer)(unsafe.Pointer(uintptr(unsafe.Pointer(thread)) + cancelReasonOffset))
+	return atomic.LoadPointer(reason) != nil
+}
+
+// watchCancel calls cancel if the thread is cancelled before the returned
+// stop func is called
+func watchCancel(thread *starlark.Thread, cancel context.CancelFunc) (stop func()) {
+	if thread == nil || !hasCancelReason {
+		return func() {}
+	}
+	if threadCancelled(thread) {
+		cancel()
+		return func() {}
+	}
+
+	var (
+		lk      sync.Mutex
+		stopped bool
+		t       *time.Timer
+	)
+	poll := func() {
+		if threadCancelled(thread) {
+			cancel()
+			return
+		}
+		lk.Lock()
+		defer lk.Unlock()
+		if !stopped {
+			t.Reset(cancelPollInterval)
+		}
+	}
+
+	lk.Lock()
+	t = time.AfterFunc(cancelPollInterval, poll)
+	lk.Unlock()
+	return func() {
+		lk.Lock()
+		defer lk.Unlock()
+		stopped = true
+		t.Stop()
+	}
+}
+
+// unpackTimeout reads a timeout argument in seconds. nil & None values
+// return def
+func unpackTimeout(v starlark.Value, def time.Duration) (time.Duration, error) {
+	if v == nil || v == starlark.None {
+		return def, nil
+	}
+	secs, ok := starlark.AsFloat(v)
+	if !ok || secs < 0 {
+		return 0, fmt.Errorf("timeout: expected a non-negative number of seconds. got: %s", v)
+	}
+	return time.Duration(secs * float64(time.Second)), nil
+}
+
+// sendWithTimeout performs a single request attempt. a positive timeout bounds
+// the entire exchange, including reading the response body. the request is
+// aborted if the thread that made it is cancelled
+func sendWithTimeout(cli *http.Client, req *http.Request, timeout time.Duration) (*http.Response, error) {
+	var (
+		ctx    context.Context
+		cancel context.CancelFunc
+	)
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(req.Context(), timeout)
+	} else {
+		ctx, cancel = context.WithCancel(req.Context())
+	}
+
+	var thread *starlark.Thread
+	if st := stateFrom(req.Context()); st != nil {
+		thread = st.thread
+	}
+	stop := watchCancel(thread, cancel)
+	res, err := cli.Do(req.WithContext(ctx))
+	stop()
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	res.Body = &cancelOnClose{ReadCloser: res.Body, cancel: cancel, thread: thread}
+	return res, nil
+}
+
+// cancelOnClose aborts reading a response body if the thread is cancelled,
+// and releases the request context when the body is closed
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+	thread *starlark.Thread
+}
+
+// Read implements the io.Reader interface
+func (c *cancelOnClose) Read(p []byte) (int, error) {
+	stop := watchCancel(c.thread, c.cancel)
+	defer stop()
+	return c.ReadCloser.Read(p)
+}
+
+// Close implements the io.Closer interface
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
+
+// sleep waits for d, returning early with an error if ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
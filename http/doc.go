@@ -11,93 +11,208 @@
             url to request
           headers dict
             optional. dictionary of headers to add to request
-          auth tuple
-            optional. (username,password) tuple for http basic authorization
-      put(url,params={},headers={},body="",form_body={},form_encoding="",json_body={},auth=()) response
+          auth tuple,BearerAuth,OAuth2ClientCredentials
+            optional. (username,password) tuple for http basic authorization, or a BearerAuth or OAuth2ClientCredentials value
+          retries int
+            optional. maximum number of times to retry a failed request. defaults to 0. hosts can cap the number of retries
+          backoff float
+            optional. seconds to wait before the first retry, doubling with each attempt. a Retry-After response header takes precedence
+          retry_on list
+            optional. response status codes to retry. defaults to [429, 502, 503, 504]
+          timeout float
+            optional. seconds to wait for the request to complete, including reading the response body. defaults to no timeout
+      put(url,params={},headers={},body="",form_body={},form_encoding="",json_body={},auth=(),files={}) response
         perform an HTTP PUT request, returning a response
         params:
           url string
             url to request
           headers dict
             optional. dictionary of headers to add to request
-          body string
-            optional. raw string body to provide to the request
+          body string,bytes
+            optional. raw string or bytes body to provide to the request
           form_body dict
             optional. dict of values that will be encoded as form data
           form_encoding string
             optional. `application/x-www-form-url-encoded` (default) or `multipart/form-data`
           json_body any
             optional. json data to supply as a request. handy for working with JSON-API's
-          auth tuple
-            optional. (username,password) tuple for http basic authorization
-      post(url,params={},headers={},body="",form_body={},form_encoding="",json_body={},auth=()) response
+          files dict
+            optional. dict of files to upload as multipart/form-data. values are either file contents, a (filename, contents) tuple, or a (filename, contents, content_type) tuple. contents can be a string or bytes
+          auth tuple,BearerAuth,OAuth2ClientCredentials
+            optional. (username,password) tuple for http basic authorization, or a BearerAuth or OAuth2ClientCredentials value
+          retries int
+            optional. maximum number of times to retry a failed request. defaults to 0. hosts can cap the number of retries
+          backoff float
+            optional. seconds to wait before the first retry, doubling with each attempt. a Retry-After response header takes precedence
+          retry_on list
+            optional. response status codes to retry. defaults to [429, 502, 503, 504]
+          timeout float
+            optional. seconds to wait for the request to complete, including reading the response body. defaults to no timeout
+      post(url,params={},headers={},body="",form_body={},form_encoding="",json_body={},auth=(),files={}) response
         perform an HTTP POST request, returning a response
         params:
           url string
             url to request
           headers dict
             optional. dictionary of headers to add to request
-          body string
-            optional. raw string body to provide to the request
+          body string,bytes
+            optional. raw string or bytes body to provide to the request
           form_body dict
             optional. dict of values that will be encoded as form data
           form_encoding string
             optional. `application/x-www-form-url-encoded` (default) or `multipart/form-data`
           json_body any
             optional. json data to supply as a request. handy for working with JSON-API's
-          auth tuple
-            optional. (username,password) tuple for http basic authorization
-      delete(url,params={},headers={},body="",form_body={},form_encoding="",json_body={},auth=()) response
+          files dict
+            optional. dict of files to upload as multipart/form-data. values are either file contents, a (filename, contents) tuple, or a (filename, contents, content_type) tuple. contents can be a string or bytes
+          auth tuple,BearerAuth,OAuth2ClientCredentials
+            optional. (username,password) tuple for http basic authorization, or a BearerAuth or OAuth2ClientCredentials value
+          retries int
+            optional. maximum number of times to retry a failed request. defaults to 0. hosts can cap the number of retries
+          backoff float
+            optional. seconds to wait before the first retry, doubling with each attempt. a Retry-After response header takes precedence
+          retry_on list
+            optional. response status codes to retry. defaults to [429, 502, 503, 504]
+          timeout float
+            optional. seconds to wait for the request to complete, including reading the response body. defaults to no timeout
+      delete(url,params={},headers={},body="",form_body={},form_encoding="",json_body={},auth=(),files={}) response
         perform an HTTP DELETE request, returning a response
         params:
           url string
             url to request
           headers dict
             optional. dictionary of headers to add to request
-          body string
-            optional. raw string body to provide to the request
+          body string,bytes
+            optional. raw string or bytes body to provide to the request
           form_body dict
             optional. dict of values that will be encoded as form data
           form_encoding string
             optional. `application/x-www-form-url-encoded` (default) or `multipart/form-data`
           json_body any
             optional. json data to supply as a request. handy for working with JSON-API's
-          auth tuple
-            optional. (username,password) tuple for http basic authorization
-      patch(url,params={},headers={},body="",form_body={},form_encoding="",json_body={},auth=()) response
+          files dict
+            optional. dict of files to upload as multipart/form-data. values are either file contents, a (filename, contents) tuple, or a (filename, contents, content_type) tuple. contents can be a string or bytes
+          auth tuple,BearerAuth,OAuth2ClientCredentials
+            optional. (username,password) tuple for http basic authorization, or a BearerAuth or OAuth2ClientCredentials value
+          retries int
+            optional. maximum number of times to retry a failed request. defaults to 0. hosts can cap the number of retries
+          backoff float
+            optional. seconds to wait before the first retry, doubling with each attempt. a Retry-After response header takes precedence
+          retry_on list
+            optional. response status codes to retry. defaults to [429, 502, 503, 504]
+          timeout float
+            optional. seconds to wait for the request to complete, including reading the response body. defaults to no timeout
+      patch(url,params={},headers={},body="",form_body={},form_encoding="",json_body={},auth=(),files={}) response
         perform an HTTP PATCH request, returning a response
         params:
           url string
             url to request
           headers dict
             optional. dictionary of headers to add to request
-          body string
-            optional. raw string body to provide to the request
+          body string,bytes
+            optional. raw string or bytes body to provide to the request
           form_body dict
             optional. dict of values that will be encoded as form data
           form_encoding string
             optional. `application/x-www-form-url-encoded` (default) or `multipart/form-data`
           json_body any
             optional. json data to supply as a request. handy for working with JSON-API's
-          auth tuple
-            optional. (username,password) tuple for http basic authorization
-      options(url,params={},headers={},body="",form_body={},form_encoding="",json_body={},auth=()) response
+          files dict
+            optional. dict of files to upload as multipart/form-data. values are either file contents, a (filename, contents) tuple, or a (filename, contents, content_type) tuple. contents can be a string or bytes
+          auth tuple,BearerAuth,OAuth2ClientCredentials
+            optional. (username,password) tuple for http basic authorization, or a BearerAuth or OAuth2ClientCredentials value
+          retries int
+            optional. maximum number of times to retry a failed request. defaults to 0. hosts can cap the number of retries
+          backoff float
+            optional. seconds to wait before the first retry, doubling with each attempt. a Retry-After response header takes precedence
+          retry_on list
+            optional. response status codes to retry. defaults to [429, 502, 503, 504]
+          timeout float
+            optional. seconds to wait for the request to complete, including reading the response body. defaults to no timeout
+      options(url,params={},headers={},body="",form_body={},form_encoding="",json_body={},auth=(),files={}) response
         perform an HTTP OPTIONS request, returning a response
         params:
           url string
             url to request
           headers dict
             optional. dictionary of headers to add to request
-          body string
-            optional. raw string body to provide to the request
+          body string,bytes
+            optional. raw string or bytes body to provide to the request
           form_body dict
             optional. dict of values that will be encoded as form data
           form_encoding string
             optional. `application/x-www-form-url-encoded` (default) or `multipart/form-data`
           json_body any
             optional. json data to supply as a request. handy for working with JSON-API's
-          auth tuple
-            optional. (username,password) tuple for http basic authorization
+          files dict
+            optional. dict of files to upload as multipart/form-data. values are either file contents, a (filename, contents) tuple, or a (filename, contents, content_type) tuple. contents can be a string or bytes
+          auth tuple,BearerAuth,OAuth2ClientCredentials
+            optional. (username,password) tuple for http basic authorization, or a BearerAuth or OAuth2ClientCredentials value
+          retries int
+            optional. maximum number of times to retry a failed request. defaults to 0. hosts can cap the number of retries
+          backoff float
+            optional. seconds to wait before the first retry, doubling with each attempt. a Retry-After response header takes precedence
+          retry_on list
+            optional. response status codes to retry. defaults to [429, 502, 503, 504]
+          timeout float
+            optional. seconds to wait for the request to complete, including reading the response body. defaults to no timeout
+      get_all(urls,concurrency=4,**kwargs) list
+        perform HTTP GET requests for a list of urls concurrently, returning a list of results in the same order as urls. a failed request doesn't stop other requests, check the error field of each result
+        params:
+          urls list
+            list of urls to request
+          concurrency int
+            optional. maximum number of requests to perform at once. the host may set a lower limit
+          kwargs
+            optional. any other argument accepted by get, applied to every request
+      paginate(url,next="link_header",max_pages=0,json=False,page_size=0,offset_param="offset",limit_param="limit",cursor_param="cursor",**kwargs) iterable
+        lazily request the pages of a paginated resource with GET requests. each page is requested as the result is iterated, yielding a response per page. iteration stops when there is no next page, a page url repeats, max_pages is reached or a response isn't ok. failed responses are yielded before stopping. errors while iterating stop iteration, and are available as the paginator's error attribute, otherwise error is None
+        params:
+          url string
+            url of the first page
+          next string,function
+            optional. how to find the next page. "link_header" follows rel="next" urls of Link headers. "json_path:<path>" reads a dot-separated path from the json page (eg: "json_path:meta.next"), following url values and adding other values to the first page url as the cursor_param query parameter. "offset" adds offset_param & limit_param query parameters, stopping on a page with fewer than page_size items. a function is called with each page and returns the next url, or None to stop
+          max_pages int
+            optional. maximum number of pages to request. 0 means no limit
+          json bool
+            optional. yield decoded json pages instead of responses. responses that aren't ok stop iteration with an error
+          page_size int
+            optional. number of items per page, required for "offset" pagination
+          offset_param string
+            optional. query parameter of the offset for "offset" pagination
+          limit_param string
+            optional. query parameter of the page size for "offset" pagination
+          cursor_param string
+            optional. query parameter of cursors read with "json_path:<path>"
+          kwargs
+            optional. any other argument accepted by get, applied to every request. params only apply to the first page url
+      Session(base_url="",headers={},auth=(),cookies={}) session
+        create a session that persists cookies across requests, adding default headers & auth to each request
+        params:
+          base_url string
+            optional. absolute url that relative request urls are resolved against
+          headers dict
+            optional. dictionary of headers to add to every request. per-request headers take precedence
+          auth tuple,BearerAuth,OAuth2ClientCredentials
+            optional. credentials used when a request doesn't specify auth. accepts the same values as request auth
+          cookies dict
+            optional. dictionary of cookies to send with requests to the base_url host. requires base_url
+      BearerAuth(token) BearerAuth
+        create auth that adds an "Authorization: Bearer" header to requests
+        params:
+          token string
+            bearer token
+      OAuth2ClientCredentials(token_url,client_id,client_secret,scopes=[]) OAuth2ClientCredentials
+        create auth that fetches access tokens using the OAuth2 client credentials grant. tokens are cached by the auth value & refreshed when they expire, reuse the same value (for example as Session auth) to avoid fetching a new token for each request
+        params:
+          token_url string
+            url of the token endpoint
+          client_id string
+            client identifier, sent with http basic authorization
+          client_secret string
+            client secret, sent with http basic authorization
+          scopes list
+            optional. list of scopes to request
 
     types:
       response
@@ -107,15 +222,70 @@
             the url that was ultimately requested (may change after redirects)
           status_code int
             response status code (for example: 200 == OK)
-          headers dict
-            dictionary of response headers
+          reason string
+            response status text (for example: "Not Found")
+          ok bool
+            True if status_code is less than 400
+          headers headers
+            case-insensitive mapping of response headers. indexing a header returns all values joined with commas. headers.get_all(name) returns a list of values. headers are read-only and never equal a dict, use dict(headers) for a dict of joined values
+          cookies dict
+            dictionary of cookies set by the response
+          history list
+            responses of any redirects followed to arrive at this response, oldest first
+          elapsed time.duration
+            time between sending the request and receiving the response headers
+          from_cache bool
+            True if the response was served from the host's response cache, either because it was still fresh or after the server confirmed it hasn't changed
           encoding string
             transfer encoding. example: "octet-stream" or "application/json"
         methods:
-          body() string
-            output response body as a string
+          body(encoding="") string
+            output response body as a string, decoded using the optional encoding argument, the charset declared in the Content-Type header, or an encoding detected from the body, in that order
+          content() bytes
+            output response body as bytes
           json()
             attempt to parse resonse body as json, returning a JSON-decoded result
+          raise_for_status()
+            fail with an error if the response status code is 400 or greater
+          iter_lines() iterable
+            iterate over the response body one line at a time, without reading the entire body into memory. line endings are removed. iteration stops at the first read error, which is available as the iterable's error attribute, otherwise error is None
+          iter_chunks(size=1024) iterable
+            iterate over the response body in bytes chunks of up to size bytes. size must be between 1 and 1048576 (1 MiB). read errors stop iteration and set the iterable's error attribute like iter_lines
+      BatchResult
+        the result of a single request made with get_all
+        fields:
+          url string
+            the requested url
+          response response
+            the response, or None if the request failed
+          error string
+            description of why the request failed, or None if it succeeded
+      session
+        a stateful http client with a persistent cookie jar. session request methods accept the same arguments as their module-level counterparts
+        fields:
+          base_url string
+            url relative request urls are resolved against
+          headers dict
+            default headers added to each request
+        methods:
+          get(url,params={},headers={},auth=()) response
+            perform an HTTP GET request, returning a response
+          put(url,params={},headers={},body="",form_body={},form_encoding="",json_body={},auth=(),files={}) response
+            perform an HTTP PUT request, returning a response
+          post(url,params={},headers={},body="",form_body={},form_encoding="",json_body={},auth=(),files={}) response
+            perform an HTTP POST request, returning a response
+          delete(url,params={},headers={},body="",form_body={},form_encoding="",json_body={},auth=(),files={}) response
+            perform an HTTP DELETE request, returning a response
+          patch(url,params={},headers={},body="",form_body={},form_encoding="",json_body={},auth=(),files={}) response
+            perform an HTTP PATCH request, returning a response
+          options(url,params={},headers={},body="",form_body={},form_encoding="",json_body={},auth=(),files={}) response
+            perform an HTTP OPTIONS request, returning a response
+          get_all(urls,concurrency=4,**kwargs) list
+            perform HTTP GET requests for a list of urls concurrently, returning a list of BatchResult
+          paginate(url,next="link_header",max_pages=0,json=False,**kwargs) iterable
+            lazily request the pages of a paginated resource, resolving relative urls against the session base_url
+          cookies(url="") dict
+            cookies the session will send to url, defaulting to the session base_url
 
 */
 package http
@@ -0,0 +1,340 @@
+package http
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"go.starlark.net/starlark"
+)
+
+// DeniedError is returned by the guards in this package when a request isn't
+// allowed
+type DeniedError struct {
+	URL    string
+	Reason string
+}
+
+// Error implements the error interface
+func (e *DeniedError) Error() string {
+	return fmt.Sprintf("http request to %s denied: %s", e.URL, e.Reason)
+}
+
+func denied(req *http.Request, format string, args ...interface{}) error {
+	return &DeniedError{URL: req.URL.String(), Reason: fmt.Sprintf(format, args...)}
+}
+
+// GuardFunc adapts a function to the RequestGuard interface
+type GuardFunc func(thread *starlark.Thread, req *http.Request) (*http.Request, error)
+
+// Allowed implements the RequestGuard interface
+func (f GuardFunc) Allowed(thread *starlark.Thread, req *http.Request) (*http.Request, error) {
+	return f(thread, req)
+}
+
+// Guards composes RequestGuards into a single guard that checks each guard in
+// order. Requests must be allowed by all guards, and each guard receives the
+// request returned by the previous guard
+func Guards(guards ...RequestGuard) RequestGuard {
+	return GuardFunc(func(thread *starlark.Thread, req *http.Request) (*http.Request, error) {
+		var err error
+		for _, g := range guards {
+			if req, err = g.Allowed(thread, req); err != nil {
+				return nil, err
+			}
+		}
+		return req, nil
+	})
+}
+
+// AllowHosts creates a guard that only allows requests to the listed
+// hostnames. Patterns starting with "*." match any subdomain, so
+// "*.example.com" matches "api.example.com" but not "example.com"
+func AllowHosts(hosts ...string) RequestGuard {
+	return GuardFunc(func(thread *starlark.Thread, req *http.Request) (*http.Request, error) {
+		if !matchHost(hosts, req.URL.Hostname()) {
+			return nil, denied(req, "host %q is not in the allowlist", req.URL.Hostname())
+		}
+		return req, nil
+	})
+}
+
+// DenyHosts creates a guard that denies requests to the listed hostnames,
+// using the same patterns as AllowHosts
+func DenyHosts(hosts ...string) RequestGuard {
+	return GuardFunc(func(thread *starlark.Thread, req *http.Request) (*http.Request, error) {
+		if matchHost(hosts, req.URL.Hostname()) {
+			return nil, denied(req, "host %q is in the denylist", req.URL.Hostname())
+		}
+		return req, nil
+	})
+}
+
+func matchHost(patterns []string, host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	for _, p := range patterns {
+		p = strings.TrimSuffix(strings.ToLower(p), ".")
+		if strings.HasPrefix(p, "*.") {
+			if strings.HasSuffix(host, p[1:]) {
+				return true
+			}
+		} else if host == p {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowSchemes creates a guard that only allows requests with the listed url
+// schemes, eg: AllowSchemes("https")
+func AllowSchemes(schemes ...string) RequestGuard {
+	return GuardFunc(func(thread *starlark.Thread, req *http.Request) (*http.Request, error) {
+		for _, s := range schemes {
+			if strings.EqualFold(s, req.URL.Scheme) {
+				return req, nil
+			}
+		}
+		return nil, denied(req, "scheme %q is not allowed", req.URL.Scheme)
+	})
+}
+
+// IPGuard restricts the IP addresses requests can connect to. Allowed checks
+// hosts that are IP literals before a request is sent. Hostnames are checked
+// when connections are dialed, after DNS resolution, which prevents DNS
+// rebinding attacks. Dial-time checks require installing the guard's
+// Transport in the http Client:
+//
+//	g := &http.IPGuard{DenyPrivate: true}
+//	http.Guard = g
+//	http.Client = &gohttp.Client{Transport: g.Transport()}
+type IPGuard struct {
+	// Allow lists networks connections are restricted to. empty allows all
+	// networks not otherwise denied
+	Allow []*net.IPNet
+	// Deny lists networks connections are forbidden to
+	Deny []*net.IPNet
+	// DenyPrivate forbids connections to loopback, private, link-local and
+	// unspecified addresses
+	DenyPrivate bool
+}
+
+// AllowCIDRs adds networks in CIDR notation to the guard's allowlist
+func (g *IPGuard) AllowCIDRs(cidrs ...string) error {
+	nets, err := parseCIDRs(cidrs)
+	if err != nil {
+		return err
+	}
+	g.Allow = append(g.Allow, nets...)
+	return nil
+}
+
+// DenyCIDRs adds networks in CIDR notation to the guard's denylist
+func (g *IPGuard) DenyCIDRs(cidrs ...string) error {
+	nets, err := parseCIDRs(cidrs)
+	if err != nil {
+		return err
+	}
+	g.Deny = append(g.Deny, nets...)
+	return nil
+}
+
+func parseCIDRs(cidrs []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(cidrs))
+	for _, c := range cidrs {
+		_, n, err := net.ParseCIDR(c)
+		if err != nil {
+			return nil, err
+		}
+		nets = append(nets, n)
+	}
+	return nets, nil
+}
+
+// Allowed implements the RequestGuard interface
+func (g *IPGuard) Allowed(thread *starlark.Thread, req *http.Request) (*http.Request, error) {
+	if ip := net.ParseIP(req.URL.Hostname()); ip != nil {
+		if err := g.CheckIP(ip); err != nil {
+			return nil, denied(req, "%s", err)
+		}
+	}
+	return req, nil
+}
+
+// CheckIP returns an error if connecting to ip isn't allowed
+func (g *IPGuard) CheckIP(ip net.IP) error {
+	if g.DenyPrivate && isPrivateIP(ip) {
+		return fmt.Errorf("address %s is private", ip)
+	}
+	for _, n := range g.Deny {
+		if n.Contains(ip) {
+			return fmt.Errorf("address %s is in denied network %s", ip, n)
+		}
+	}
+	if len(g.Allow) == 0 {
+		return nil
+	}
+	for _, n := range g.Allow {
+		if n.Contains(ip) {
+			return nil
+		}
+	}
+	return fmt.Errorf("address %s is not in an allowed network", ip)
+}
+
+// privateNets are the private address ranges of RFC 1918 and RFC 4193
+var privateNets = mustParseCIDRs("10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "fc00::/7")
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	nets, err := parseCIDRs(cidrs)
+	if err != nil {
+		panic(err)
+	}
+	return nets
+}
+
+func isPrivateIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsUnspecified() {
+		return true
+	}
+	for _, n := range privateNets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// Control checks the address of a connection before it's established. Use as
+// the Control func of a net.Dialer
+func (g *IPGuard) Control(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return fmt.Errorf("http connection denied: invalid address %q", address)
+	}
+	if err := g.CheckIP(ip); err != nil {
+		return fmt.Errorf("http connection denied: %s", err)
+	}
+	return nil
+}
+
+// Transport creates an http transport that checks addresses with the guard
+// when dialing connections. Proxies are disabled, as connecting through a
+// proxy would bypass address checks
+func (g *IPGuard) Transport() *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   g.Control,
+	}
+	// match the settings of http.DefaultTransport
+	return &http.Transport{
+		DialContext:           dialer.DialContext,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+}
+
+// keyRequestCount is the thread-local key for a thread's request counter
+const keyRequestCount = "http.requestCount"
+
+// RequestLimit is a guard that limits the number of requests each starlark
+// thread can make
+type RequestLimit struct {
+	Max int
+}
+
+// requestCounter counts requests made by a thread. counters are locked as
+// redirects may be checked concurrently
+type requestCounter struct {
+	lk sync.Mutex
+	n  int
+}
+
+// Allowed implements the RequestGuard interface
+func (l RequestLimit) Allowed(thread *starlark.Thread, req *http.Request) (*http.Request, error) {
+	if thread == nil {
+		return req, nil
+	}
+	// requests sent by the module carry the thread's counter, as guards may be
+	// called concurrently, where reading thread locals isn't safe
+	var count *requestCounter
+	if st := stateFrom(req.Context()); st != nil && st.thread == thread {
+		count = st.count
+	} else {
+		count = requestCounterFor(thread)
+	}
+
+	count.lk.Lock()
+	defer count.lk.Unlock()
+	if count.n >= l.Max {
+		return nil, denied(req, "exceeded limit of %d requests", l.Max)
+	}
+	count.n++
+	return req, nil
+}
+
+// requestCounterFor returns a thread's request counter, creating it if it
+// doesn't exist. must be called on the thread's goroutine
+func requestCounterFor(thread *starlark.Thread) *requestCounter {
+	localsLk.Lock()
+	defer localsLk.Unlock()
+	count, _ := thread.Local(keyRequestCount).(*requestCounter)
+	if count == nil {
+		count = &requestCounter{}
+		thread.SetLocal(keyRequestCount, count)
+	}
+	return count
+}
+
+// RequestCount returns the number of requests a thread has made that were
+// counted by a RequestLimit guard
+func RequestCount(thread *starlark.Thread) int {
+	localsLk.Lock()
+	count, ok := thread.Local(keyRequestCount).(*requestCounter)
+	localsLk.Unlock()
+	if ok {
+		count.lk.Lock()
+		defer count.lk.Unlock()
+		return count.n
+	}
+	return 0
+}
+
+// checkRedirect applies the module's RequestGuard to redirects, which would
+// otherwise bypass the guard. next is the client's existing redirect policy
+func (m *Module) checkRedirect(next func(req *http.Request, via []*http.Request) error) func(req *http.Request, via []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		st := stateFrom(req.Context())
+		if m.rg != nil {
+			var thread *starlark.Thread
+			if st != nil {
+				thread = st.thread
+			}
+			if _, err := m.rg.Allowed(thread, req); err != nil {
+				// denied redirects are never sent, observe them here
+				if m.observer != nil && st != nil {
+					m.deliver(st, RequestRecord{Method: req.Method, URL: req.URL.String(), Err: err})
+				}
+				return err
+			}
+		}
+		if next != nil {
+			return next(req, via)
+		}
+		// match the default policy of go's http client
+		if len(via) >= 10 {
+			return fmt.Errorf("stopped after 10 redirects")
+		}
+		return nil
+	}
+}
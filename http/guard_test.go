@@ -0,0 +1,241 @@
+package http
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/qri-io/starlib/testdata"
+	"go.starlark.net/starlark"
+)
+
+func TestMatchHost(t *testing.T) {
+	patterns := []string{"example.com", "*.qri.io", "API.Service.org."}
+	cases := []struct {
+		host  string
+		match bool
+	}{
+		{"example.com", true},
+		{"EXAMPLE.com", true},
+		{"www.example.com", false},
+		{"qri.io", false},
+		{"api.qri.io", true},
+		{"a.b.qri.io", true},
+		{"evilqri.io", false},
+		{"api.service.org", true},
+	}
+
+	for i, c := range cases {
+		if got := matchHost(patterns, c.host); got != c.match {
+			t.Errorf("case %d %q. expected: %t, got: %t", i, c.host, c.match, got)
+		}
+	}
+}
+
+func TestGuards(t *testing.T) {
+	g := Guards(AllowSchemes("https"), AllowHosts("*.example.com"), DenyHosts("admin.example.com"))
+	cases := []struct {
+		url, err string
+	}{
+		{"https://api.example.com/a", ""},
+		{"http://api.example.com/a", `http request to http://api.example.com/a denied: scheme "http" is not allowed`},
+		{"https://example.org", `http request to https://example.org denied: host "example.org" is not in the allowlist`},
+		{"https://admin.example.com", `http request to https://admin.example.com denied: host "admin.example.com" is in the denylist`},
+	}
+
+	for i, c := range cases {
+		req := httptest.NewRequest("GET", c.url, nil)
+		_, err := g.Allowed(nil, req)
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: %q, got: %v", i, c.err, err)
+		}
+	}
+}
+
+func TestIPGuardCheckIP(t *testing.T) {
+	g := &IPGuard{DenyPrivate: true}
+	if err := g.AllowCIDRs("8.8.0.0/16", "2001:db8::/32"); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.DenyCIDRs("8.8.4.0/24"); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.DenyCIDRs("not a cidr"); err == nil {
+		t.Error("expected invalid CIDR to error")
+	}
+
+	cases := []struct {
+		ip, err string
+	}{
+		{"8.8.8.8", ""},
+		{"2001:db8::1", ""},
+		{"8.8.4.4", "address 8.8.4.4 is in denied network 8.8.4.0/24"},
+		{"1.1.1.1", "address 1.1.1.1 is not in an allowed network"},
+		{"127.0.0.1", "address 127.0.0.1 is private"},
+		{"10.0.0.1", "address 10.0.0.1 is private"},
+		{"169.254.169.254", "address 169.254.169.254 is private"},
+		{"::1", "address ::1 is private"},
+		{"0.0.0.0", "address 0.0.0.0 is private"},
+	}
+
+	for i, c := range cases {
+		err := g.CheckIP(net.ParseIP(c.ip))
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: %q, got: %v", i, c.err, err)
+		}
+	}
+}
+
+func TestIPGuardDial(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	g := &IPGuard{DenyPrivate: true}
+	if _, err := g.Allowed(nil, httptest.NewRequest("GET", ts.URL, nil)); err == nil {
+		t.Error("expected request to a private IP literal to be denied")
+	}
+
+	// hostnames pass Allowed, but are denied once resolved at dial time
+	_, port, _ := net.SplitHostPort(ts.Listener.Addr().String())
+	u := "http://localhost:" + port
+	if _, err := g.Allowed(nil, httptest.NewRequest("GET", u, nil)); err != nil {
+		t.Fatal(err)
+	}
+	cli := &http.Client{Transport: g.Transport()}
+	_, err := cli.Get(u)
+	if err == nil || !strings.Contains(err.Error(), "http connection denied: address") {
+		t.Errorf("expected dial to be denied, got: %v", err)
+	}
+
+	if _, err := (&http.Client{Transport: (&IPGuard{}).Transport()}).Get(u); err != nil {
+		t.Errorf("expected empty guard to allow connections, got: %v", err)
+	}
+}
+
+func TestModuleGuard(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, "http://denied.example/", http.StatusFound)
+		}
+	}))
+	defer ts.Close()
+
+	prev := Guard
+	defer func() { Guard = prev }()
+	Guard = Guards(DenyHosts("denied.example"), RequestLimit{Max: 3})
+
+	thread := &starlark.Thread{Load: testdata.NewLoader(LoadModule, ModuleName)}
+	script := `load('http.star', 'http')
+def req(path):
+  http.get(url + path)
+`
+	globals, err := starlark.ExecFile(thread, "guard.star", script, starlark.StringDict{"url": starlark.String(ts.URL)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	call := func(path string) error {
+		_, err := starlark.Call(thread, globals["req"], starlark.Tuple{starlark.String(path)}, nil)
+		return err
+	}
+
+	if err := call("/"); err != nil {
+		t.Fatal(err)
+	}
+	// redirects are checked by the guard. the denied redirect isn't counted
+	if err := call("/redirect"); err == nil || !strings.Contains(err.Error(), `host "denied.example" is in the denylist`) {
+		t.Errorf("expected redirect to be denied, got: %v", err)
+	}
+	if RequestCount(thread) != 2 {
+		t.Errorf("expected 2 requests to be counted, got: %d", RequestCount(thread))
+	}
+	if err := call("/"); err != nil {
+		t.Fatal(err)
+	}
+	if err := call("/"); err == nil || !strings.Contains(err.Error(), "exceeded limit of 3 requests") {
+		t.Errorf("expected request limit error, got: %v", err)
+	}
+}
+
+func TestModuleGuardRetries(t *testing.T) {
+	ts := retryServer()
+	defer ts.Close()
+
+	prev := Guard
+	defer func() { Guard = prev }()
+	Guard = RequestLimit{Max: 2}
+
+	thread := &starlark.Thread{Load: testdata.NewLoader(LoadModule, ModuleName)}
+	script := `load('http.star', 'http')
+http.get(url + "/flaky/limited", retries=2, backoff=0)
+`
+	// the request needs three attempts, and each retry counts towards the limit
+	_, err := starlark.ExecFile(thread, "guard.star", script, starlark.StringDict{"url": starlark.String(ts.URL)})
+	if err == nil || !strings.Contains(err.Error(), "exceeded limit of 2 requests") {
+		t.Errorf("expected retry to be denied, got: %v", err)
+	}
+	if RequestCount(thread) != 2 {
+		t.Errorf("expected 2 requests to be counted, got: %d", RequestCount(thread))
+	}
+}
+
+func TestIsPrivateIP(t *testing.T) {
+	cases := []struct {
+		ip      string
+		private bool
+	}{
+		{"127.0.0.1", true},
+		{"10.1.2.3", true},
+		{"172.16.0.1", true},
+		{"172.31.255.255", true},
+		{"172.32.0.1", false},
+		{"192.168.1.1", true},
+		{"169.254.169.254", true},
+		{"0.0.0.0", true},
+		{"8.8.8.8", false},
+		{"::1", true},
+		{"fd00::1", true},
+		{"fe80::1", true},
+		{"2001:db8::1", false},
+	}
+
+	for _, c := range cases {
+		if got := isPrivateIP(net.ParseIP(c.ip)); got != c.private {
+			t.Errorf("%s: expected private to be %t, got: %t", c.ip, c.private, got)
+		}
+	}
+}
+
+// TestModuleGuardRedirects pins that guards check every redirect, which
+// changed guards that previously only saw the first request
+func TestModuleGuardRedirects(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/a":
+			http.Redirect(w, r, "/b", http.StatusFound)
+		case "/b":
+			http.Redirect(w, r, "/c", http.StatusFound)
+		}
+	}))
+	defer ts.Close()
+
+	var seen []string
+	prev := Guard
+	defer func() { Guard = prev }()
+	Guard = GuardFunc(func(thread *starlark.Thread, req *http.Request) (*http.Request, error) {
+		seen = append(seen, req.URL.Path)
+		return req, nil
+	})
+
+	thread := &starlark.Thread{Load: testdata.NewLoader(LoadModule, ModuleName)}
+	script := `load('http.star', 'http')
+http.get(url + "/a")
+`
+	if _, err := starlark.ExecFile(thread, "guard.star", script, starlark.StringDict{"url": starlark.String(ts.URL)}); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(seen, ",") != "/a,/b,/c" {
+		t.Errorf("expected guard to check the request and both redirects. got: %v", seen)
+	}
+}
@@ -0,0 +1,163 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"go.starlark.net/starlark"
+)
+
+// Headers is a read-only, case-insensitive starlark mapping of http headers.
+// Indexing a header returns all values joined with commas, get_all returns
+// each value separately
+type Headers http.Header
+
+var (
+	_ starlark.Value           = Headers(nil)
+	_ starlark.IterableMapping = Headers(nil)
+	_ starlark.HasAttrs        = Headers(nil)
+)
+
+// String implements the starlark.Value interface
+func (h Headers) String() string {
+	var sb strings.Builder
+	sb.WriteString("headers({")
+	for i, key := range h.sortedKeys() {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		fmt.Fprintf(&sb, "%s: %s", starlark.String(key), starlark.String(strings.Join(h[key], ",")))
+	}
+	sb.WriteString("})")
+	return sb.String()
+}
+
+// Type implements the starlark.Value interface
+func (h Headers) Type() string { return "headers" }
+
+// Freeze implements the starlark.Value interface, headers are immutable
+func (h Headers) Freeze() {}
+
+// Truth implements the starlark.Value interface
+func (h Headers) Truth() starlark.Bool { return len(h) > 0 }
+
+// Hash implements the starlark.Value interface
+func (h Headers) Hash() (uint32, error) { return 0, fmt.Errorf("unhashable type: headers") }
+
+// Len returns the number of distinct header names
+func (h Headers) Len() int { return len(h) }
+
+// Get implements the starlark.Mapping interface
+func (h Headers) Get(k starlark.Value) (v starlark.Value, found bool, err error) {
+	key, ok := starlark.AsString(k)
+	if !ok {
+		return nil, false, fmt.Errorf("headers: expected string key. got: '%s'", k.Type())
+	}
+	vals, ok := h[http.CanonicalHeaderKey(key)]
+	if !ok {
+		return starlark.None, false, nil
+	}
+	return starlark.String(strings.Join(vals, ",")), true, nil
+}
+
+// Iterate implements the starlark.Iterable interface, yielding header names
+func (h Headers) Iterate() starlark.Iterator {
+	keys := h.sortedKeys()
+	vals := make([]starlark.Value, len(keys))
+	for i, key := range keys {
+		vals[i] = starlark.String(key)
+	}
+	return starlark.NewList(vals).Iterate()
+}
+
+// Items implements the starlark.IterableMapping interface
+func (h Headers) Items() []starlark.Tuple {
+	keys := h.sortedKeys()
+	items := make([]starlark.Tuple, len(keys))
+	for i, key := range keys {
+		items[i] = starlark.Tuple{starlark.String(key), starlark.String(strings.Join(h[key], ","))}
+	}
+	return items
+}
+
+// Attr implements the starlark.HasAttrs interface
+func (h Headers) Attr(name string) (starlark.Value, error) {
+	switch name {
+	case "get":
+		return starlark.NewBuiltin("get", h.get), nil
+	case "get_all":
+		return starlark.NewBuiltin("get_all", h.getAll), nil
+	case "keys":
+		return starlark.NewBuiltin("keys", h.keys), nil
+	case "items":
+		return starlark.NewBuiltin("items", h.items), nil
+	}
+	return nil, nil
+}
+
+// AttrNames implements the starlark.HasAttrs interface
+func (h Headers) AttrNames() []string {
+	return []string{"get", "get_all", "items", "keys"}
+}
+
+func (h Headers) sortedKeys() []string {
+	keys := make([]string, 0, len(h))
+	for key := range h {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (h Headers) get(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var (
+		key string
+		def starlark.Value = starlark.None
+	)
+	if err := starlark.UnpackArgs("get", args, kwargs, "key", &key, "default?", &def); err != nil {
+		return nil, err
+	}
+	if v, found, _ := h.Get(starlark.String(key)); found {
+		return v, nil
+	}
+	return def, nil
+}
+
+func (h Headers) getAll(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var key string
+	if err := starlark.UnpackArgs("get_all", args, kwargs, "key", &key); err != nil {
+		return nil, err
+	}
+	vals := h[http.CanonicalHeaderKey(key)]
+	list := make([]starlark.Value, len(vals))
+	for i, v := range vals {
+		list[i] = starlark.String(v)
+	}
+	return starlark.NewList(list), nil
+}
+
+func (h Headers) keys(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if err := starlark.UnpackArgs("keys", args, kwargs); err != nil {
+		return nil, err
+	}
+	keys := h.sortedKeys()
+	list := make([]starlark.Value, len(keys))
+	for i, key := range keys {
+		list[i] = starlark.String(key)
+	}
+	return starlark.NewList(list), nil
+}
+
+func (h Headers) items(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if err := starlark.UnpackArgs("items", args, kwargs); err != nil {
+		return nil, err
+	}
+	items := h.Items()
+	list := make([]starlark.Value, len(items))
+	for i, item := range items {
+		list[i] = item
+	}
+	return starlark.NewList(list), nil
+}
@@ -2,16 +2,20 @@ package http
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"mime/multipart"
 	"net/http"
+	"net/textproto"
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	util "github.com/qri-io/starlib/util"
+	startime "go.starlark.net/lib/time"
 	"go.starlark.net/starlark"
 	"go.starlark.net/starlarkstruct"
 )
@@ -32,6 +36,32 @@ var (
 	// Guard is a global RequestGuard used in LoadModule. override with a custom
 	// implementation before calling LoadModule
 	Guard RequestGuard
+	// Timeout is the default time limit for requests made by a module created
+	// with LoadModule, including reading the response body. Scripts can
+	// override the timeout per-request. Zero means no timeout
+	Timeout time.Duration
+	// MaxBodySize is the maximum number of bytes a module created with
+	// LoadModule will read from a response body. Reading a larger body fails
+	// with an ErrBodyTooLarge error. Zero means no limit
+	MaxBodySize int64
+	// MaxConcurrency caps the number of requests get_all performs at once for
+	// modules created with LoadModule. Zero means no limit
+	MaxConcurrency = 16
+	// Cache is the ResponseCache used in LoadModule. nil disables caching
+	Cache ResponseCache
+	// Observer is the RequestObserver used in LoadModule. nil disables
+	// observing requests
+	Observer RequestObserver
+	// Retry is the default RetryPolicy used in LoadModule. scripts can override
+	// retries, backoff and status codes per-request. Retry.MaxWait caps the
+	// time scripts can spend waiting on retries, and Retry.MaxRetries caps the
+	// number of retries they can ask for
+	Retry = RetryPolicy{
+		Backoff:    500 * time.Millisecond,
+		RetryOn:    []int{http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout},
+		MaxWait:    30 * time.Second,
+		MaxRetries: 10,
+	}
 )
 
 // Encodings for form data.
@@ -44,10 +74,18 @@ const (
 
 // LoadModule creates an http Module
 func LoadModule() (starlark.StringDict, error) {
-	var m = &Module{cli: Client}
+	var m = &Module{retry: Retry, timeout: Timeout, maxBodySize: MaxBodySize, maxConcurrency: MaxConcurrency, cache: Cache, observer: Observer}
 	if Guard != nil {
 		m.rg = Guard
 	}
+	// copy the client to check redirects with the guard without modifying the
+	// package-level client
+	cli := *Client
+	cli.CheckRedirect = m.checkRedirect(Client.CheckRedirect)
+	if m.observer != nil {
+		cli.Transport = &observedTransport{m: m, next: cli.Transport}
+	}
+	m.cli = &cli
 	ns := starlark.StringDict{
 		"http": m.Struct(),
 	}
@@ -55,7 +93,11 @@ func LoadModule() (starlark.StringDict, error) {
 }
 
 // RequestGuard controls access to http by checking before making requests
-// if Allowed returns an error the request will be denied
+// if Allowed returns an error the request will be denied. Guards also check
+// each redirect a request follows, where the returned request is ignored.
+// Redirects of requests made with get_all are checked concurrently.
+// AllowHosts, DenyHosts, AllowSchemes, IPGuard and RequestLimit implement
+// common policies, and can be combined with Guards
 type RequestGuard interface {
 	Allowed(thread *starlark.Thread, req *http.Request) (*http.Request, error)
 }
@@ -63,8 +105,14 @@ type RequestGuard interface {
 // Module joins http tools to a dataset, allowing dataset
 // to follow along with http requests
 type Module struct {
-	cli *http.Client
-	rg  RequestGuard
+	cli            *http.Client
+	rg             RequestGuard
+	retry          RetryPolicy
+	timeout        time.Duration
+	maxBodySize    int64
+	maxConcurrency int
+	cache          ResponseCache
+	observer       RequestObserver
 }
 
 // Struct returns this module's methods as a starlark Struct
@@ -75,70 +123,182 @@ func (m *Module) Struct() *starlarkstruct.Struct {
 // StringDict returns all module methods in a starlark.StringDict
 func (m *Module) StringDict() starlark.StringDict {
 	return starlark.StringDict{
-		"get":     starlark.NewBuiltin("get", m.reqMethod("get")),
-		"put":     starlark.NewBuiltin("put", m.reqMethod("put")),
-		"post":    starlark.NewBuiltin("post", m.reqMethod("post")),
-		"delete":  starlark.NewBuiltin("delete", m.reqMethod("delete")),
-		"patch":   starlark.NewBuiltin("patch", m.reqMethod("patch")),
-		"options": starlark.NewBuiltin("options", m.reqMethod("options")),
+		"get":      starlark.NewBuiltin("get", m.reqMethod("get")),
+		"put":      starlark.NewBuiltin("put", m.reqMethod("put")),
+		"post":     starlark.NewBuiltin("post", m.reqMethod("post")),
+		"delete":   starlark.NewBuiltin("delete", m.reqMethod("delete")),
+		"patch":    starlark.NewBuiltin("patch", m.reqMethod("patch")),
+		"options":  starlark.NewBuiltin("options", m.reqMethod("options")),
+		"get_all":  starlark.NewBuiltin("get_all", m.getAll(nil)),
+		"paginate": starlark.NewBuiltin("paginate", m.paginate(nil)),
+
+		"Session": starlark.NewBuiltin("Session", m.newSession),
+
+		"BearerAuth":              starlark.NewBuiltin("BearerAuth", newBearerAuth),
+		"OAuth2ClientCredentials": starlark.NewBuiltin("OAuth2ClientCredentials", newOAuth2ClientCredentials),
 	}
 }
 
 // reqMethod is a factory function for generating starlark builtin functions for different http request methods
 func (m *Module) reqMethod(method string) func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 	return func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
-		var (
-			urlv         starlark.String
-			params       = &starlark.Dict{}
-			headers      = &starlark.Dict{}
-			formBody     = &starlark.Dict{}
-			formEncoding starlark.String
-			auth         starlark.Tuple
-			body         starlark.String
-			jsonBody     starlark.Value
-		)
-
-		if err := starlark.UnpackArgs(method, args, kwargs, "url", &urlv, "params?", &params, "headers", &headers, "body", &body, "form_body", &formBody, "form_encoding", &formEncoding, "json_body", &jsonBody, "auth", &auth); err != nil {
-			return nil, err
-		}
-
-		rawurl, err := AsString(urlv)
+		req, opts, err := m.newRequest(thread, method, args, kwargs, nil)
 		if err != nil {
 			return nil, err
 		}
-		if err = setQueryParams(&rawurl, params); err != nil {
-			return nil, err
+		return m.do(thread, m.cli, req, opts)
+	}
+}
+
+// reqOpts holds per-request settings that control how a request is performed
+type reqOpts struct {
+	retry   RetryPolicy
+	timeout time.Duration
+}
+
+// newRequest unpacks starlark arguments into an http request, checking the
+// request against the module's RequestGuard. A non-nil session resolves
+// relative urls and supplies default headers and auth
+func (m *Module) newRequest(thread *starlark.Thread, method string, args starlark.Tuple, kwargs []starlark.Tuple, s *Session) (*http.Request, reqOpts, error) {
+	var (
+		urlv         starlark.String
+		params       = &starlark.Dict{}
+		headers      = &starlark.Dict{}
+		formBody     = &starlark.Dict{}
+		formEncoding starlark.String
+		auth         starlark.Value
+		body         starlark.Value
+		files        = &starlark.Dict{}
+		jsonBody     starlark.Value
+		retries      starlark.Value
+		backoff      starlark.Value
+		retryOn      starlark.Value
+		timeout      starlark.Value
+		opts         = reqOpts{retry: m.retry}
+	)
+
+	if err := starlark.UnpackArgs(method, args, kwargs, "url", &urlv, "params?", &params, "headers", &headers, "body", &body, "form_body", &formBody, "form_encoding", &formEncoding, "json_body", &jsonBody, "auth", &auth, "files?", &files, "retries?", &retries, "backoff?", &backoff, "retry_on?", &retryOn, "timeout?", &timeout); err != nil {
+		return nil, opts, err
+	}
+	if err := opts.retry.unpackRetryArgs(retries, backoff, retryOn); err != nil {
+		return nil, opts, fmt.Errorf("%s: %s", method, err)
+	}
+	var err error
+	if opts.timeout, err = unpackTimeout(timeout, m.timeout); err != nil {
+		return nil, opts, fmt.Errorf("%s: %s", method, err)
+	}
+
+	rawurl, err := AsString(urlv)
+	if err != nil {
+		return nil, opts, err
+	}
+	if s != nil {
+		if rawurl, err = s.resolveURL(rawurl); err != nil {
+			return nil, opts, err
 		}
+	}
+	if err = setQueryParams(&rawurl, params); err != nil {
+		return nil, opts, err
+	}
 
-		req, err := http.NewRequest(strings.ToUpper(method), rawurl, nil)
+	req, err := http.NewRequest(strings.ToUpper(method), rawurl, nil)
+	if err != nil {
+		return nil, opts, err
+	}
+	if m.rg != nil {
+		req, err = m.rg.Allowed(thread, req)
 		if err != nil {
-			return nil, err
-		}
-		if m.rg != nil {
-			req, err = m.rg.Allowed(thread, req)
-			if err != nil {
-				return nil, err
-			}
+			return nil, opts, err
 		}
+	}
 
-		if err = setHeaders(req, headers); err != nil {
-			return nil, err
+	if err = setHeaders(req, headers); err != nil {
+		return nil, opts, err
+	}
+	if s != nil {
+		s.setDefaults(req)
+		if isEmptyAuth(auth) {
+			auth = s.auth
 		}
-		if err = setAuth(req, auth); err != nil {
-			return nil, err
+	}
+	if err = m.setRequestAuth(thread, req, auth); err != nil {
+		return nil, opts, err
+	}
+	if err = setBody(req, body, formBody, formEncoding, jsonBody, files); err != nil {
+		return nil, opts, err
+	}
+
+	return req, opts, nil
+}
+
+// do performs a request with the given client, wrapping the result as a
+// starlark response value
+func (m *Module) do(thread *starlark.Thread, cli *http.Client, req *http.Request, opts reqOpts) (starlark.Value, error) {
+	r, err := m.send(thread, cli, req, opts)
+	if err != nil {
+		return nil, err
+	}
+	return r.Struct(), nil
+}
+
+// send performs a request with the given client. requests are bound to the
+// thread's context. GET and HEAD requests use the module cache, if one is set.
+// requests that don't carry thread state must be sent on the thread's
+// goroutine
+func (m *Module) send(thread *starlark.Thread, cli *http.Client, req *http.Request, opts reqOpts) (*Response, error) {
+	st := stateFrom(req.Context())
+	if st == nil {
+		st = stateFor(thread)
+		req = req.WithContext(withState(contextFor(thread), st))
+	}
+
+	var (
+		cached       *cacheEntry
+		revalidating bool
+		useCache     = m.cache != nil && cacheable(req)
+	)
+	if useCache {
+		if cached = lookup(m.cache, req, cli); cached != nil {
+			if cached.fresh(req, time.Now()) {
+				if m.observer != nil {
+					m.deliver(st, RequestRecord{Method: req.Method, URL: req.URL.String(), StatusCode: cached.StatusCode, FromCache: true})
+				}
+				return &Response{Response: *cached.response(req), FromCache: true}, nil
+			}
+			revalidating = cached.revalidate(req)
 		}
-		if err = setBody(req, body, formBody, formEncoding, jsonBody); err != nil {
-			return nil, err
+	}
+	if revalidating {
+		req = req.WithContext(context.WithValue(req.Context(), keyRevalidating{}, true))
+	}
+
+	// each attempt and redirect is observed by the client's transport
+	var elapsed time.Duration
+	// retries are requests too, and must pass the guard like the first attempt
+	var check func(*http.Request) error
+	if m.rg != nil {
+		check = func(req *http.Request) error {
+			_, err := m.rg.Allowed(st.thread, req)
+			return err
 		}
+	}
+	res, err := opts.retry.do(req, check, func(req *http.Request) (*http.Response, error) {
+		start := time.Now()
+		defer func() { elapsed = time.Since(start) }()
+		return sendWithTimeout(cli, req, opts.timeout)
+	})
+	if err != nil {
+		return nil, err
+	}
+	res.Body = limitBody(res.Body, m.maxBodySize)
 
-		res, err := m.cli.Do(req)
-		if err != nil {
+	fromCache := false
+	if useCache {
+		if res, fromCache, err = m.cacheResponse(req, res, cached, revalidating); err != nil {
 			return nil, err
 		}
-
-		r := &Response{*res}
-		return r.Struct(), nil
 	}
+	return &Response{Response: *res, Elapsed: elapsed, FromCache: fromCache}, nil
 }
 
 func setQueryParams(rawurl *string, params *starlark.Dict) error {
@@ -227,16 +387,22 @@ func setHeaders(req *http.Request, headers *starlark.Dict) error {
 	return nil
 }
 
-func setBody(req *http.Request, body starlark.String, formData *starlark.Dict, formEncoding starlark.String, jsondata starlark.Value) error {
-	if !util.IsEmptyString(body) {
-		uq, err := strconv.Unquote(body.String())
-		if err != nil {
-			return err
-		}
-		req.Body = ioutil.NopCloser(strings.NewReader(uq))
+func setBody(req *http.Request, body starlark.Value, formData *starlark.Dict, formEncoding starlark.String, jsondata starlark.Value, files *starlark.Dict) error {
+	var raw string
+	switch b := body.(type) {
+	case nil, starlark.NoneType:
+	case starlark.String:
+		raw = string(b)
+	case starlark.Bytes:
+		raw = string(b)
+	default:
+		return fmt.Errorf("expected body to be a string or bytes. got: '%s'", body.Type())
+	}
+	if raw != "" {
+		req.Body = ioutil.NopCloser(strings.NewReader(raw))
 		// Specifying the Content-Length ensures that https://go.dev/src/net/http/transfer.go doesnt specify Transfer-Encoding: chunked which is not supported by some endpoints.
 		// This is required when using ioutil.NopCloser method for the request body (see ShouldSendChunkedRequestBody() in the library mentioned above).
-		req.ContentLength = int64(len(uq))
+		req.ContentLength = int64(len(raw))
 
 		return nil
 	}
@@ -256,27 +422,39 @@ func setBody(req *http.Request, body starlark.String, formData *starlark.Dict, f
 		req.ContentLength = int64(len(data))
 	}
 
-	if formData != nil && formData.Len() > 0 {
+	hasFiles := files != nil && files.Len() > 0
+	if (formData != nil && formData.Len() > 0) || hasFiles {
 		form := url.Values{}
-		for _, key := range formData.Keys() {
-			keystr, err := AsString(key)
-			if err != nil {
-				return err
-			}
+		if formData != nil {
+			for _, key := range formData.Keys() {
+				keystr, err := AsString(key)
+				if err != nil {
+					return err
+				}
 
-			val, _, err := formData.Get(key)
-			if err != nil {
-				return err
-			}
-			if val.Type() != "string" {
-				return fmt.Errorf("expected param value for key '%s' to be a string. got: '%s'", key, val.Type())
-			}
-			valstr, err := AsString(val)
-			if err != nil {
-				return err
+				val, _, err := formData.Get(key)
+				if err != nil {
+					return err
+				}
+				if val.Type() != "string" {
+					return fmt.Errorf("expected param value for key '%s' to be a string. got: '%s'", key, val.Type())
+				}
+				valstr, err := AsString(val)
+				if err != nil {
+					return err
+				}
+
+				form.Add(keystr, valstr)
 			}
+		}
 
-			form.Add(keystr, valstr)
+		if hasFiles {
+			switch formEncoding {
+			case formEncodingMultipart, "":
+				formEncoding = formEncodingMultipart
+			default:
+				return fmt.Errorf("files require %s form encoding. got: %s", formEncodingMultipart, formEncoding)
+			}
 		}
 
 		var contentType string
@@ -289,8 +467,6 @@ func setBody(req *http.Request, body starlark.String, formData *starlark.Dict, f
 		case formEncodingMultipart:
 			var b bytes.Buffer
 			mw := multipart.NewWriter(&b)
-			defer mw.Close()
-
 			contentType = mw.FormDataContentType()
 
 			for k, values := range form {
@@ -304,8 +480,17 @@ func setBody(req *http.Request, body starlark.String, formData *starlark.Dict, f
 					}
 				}
 			}
+			if hasFiles {
+				if err := writeFiles(mw, files); err != nil {
+					return err
+				}
+			}
+			if err := mw.Close(); err != nil {
+				return err
+			}
 
 			req.Body = ioutil.NopCloser(&b)
+			req.ContentLength = int64(b.Len())
 
 		default:
 			return fmt.Errorf("unknown form encoding: %s", formEncoding)
@@ -319,10 +504,77 @@ func setBody(req *http.Request, body starlark.String, formData *starlark.Dict, f
 	return nil
 }
 
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// writeFiles adds file parts to a multipart body. files maps field names to
+// either file contents, or a (filename, contents) or
+// (filename, contents, content_type) tuple. contents can be a string or bytes
+func writeFiles(mw *multipart.Writer, files *starlark.Dict) error {
+	for _, item := range files.Items() {
+		field, ok := starlark.AsString(item[0])
+		if !ok {
+			return fmt.Errorf("expected files key to be a string. got: '%s'", item[0].Type())
+		}
+
+		var (
+			filename    = field
+			contents    starlark.Value
+			contentType = "application/octet-stream"
+		)
+		switch v := item[1].(type) {
+		case starlark.String, starlark.Bytes:
+			contents = v
+		case starlark.Tuple:
+			if len(v) < 2 || len(v) > 3 {
+				return fmt.Errorf("expected files value for key '%s' to be a (filename, contents) or (filename, contents, content_type) tuple", field)
+			}
+			if filename, ok = starlark.AsString(v[0]); !ok {
+				return fmt.Errorf("expected filename for key '%s' to be a string. got: '%s'", field, v[0].Type())
+			}
+			contents = v[1]
+			if len(v) == 3 {
+				if contentType, ok = starlark.AsString(v[2]); !ok {
+					return fmt.Errorf("expected content_type for key '%s' to be a string. got: '%s'", field, v[2].Type())
+				}
+			}
+		default:
+			return fmt.Errorf("expected files value for key '%s' to be a string, bytes or tuple. got: '%s'", field, item[1].Type())
+		}
+
+		var data string
+		switch c := contents.(type) {
+		case starlark.String:
+			data = string(c)
+		case starlark.Bytes:
+			data = string(c)
+		default:
+			return fmt.Errorf("expected file contents for key '%s' to be a string or bytes. got: '%s'", field, contents.Type())
+		}
+
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, quoteEscaper.Replace(field), quoteEscaper.Replace(filename)))
+		h.Set("Content-Type", contentType)
+		w, err := mw.CreatePart(h)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write([]byte(data)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Response represents an HTTP response, wrapping a go http.Response with
 // starlark methods
 type Response struct {
 	http.Response
+	// Elapsed is the time between sending the request and receiving response
+	// headers
+	Elapsed time.Duration
+	// FromCache is true for responses served from the module's ResponseCache,
+	// including responses revalidated with a conditional request
+	FromCache bool
 }
 
 // Struct turns a response into a *starlark.Struct
@@ -330,11 +582,21 @@ func (r *Response) Struct() *starlarkstruct.Struct {
 	return starlarkstruct.FromStringDict(starlarkstruct.Default, starlark.StringDict{
 		"url":         starlark.String(r.Request.URL.String()),
 		"status_code": starlark.MakeInt(r.StatusCode),
-		"headers":     r.HeadersDict(),
+		"reason":      starlark.String(r.Reason()),
+		"ok":          starlark.Bool(r.OK()),
+		"headers":     Headers(r.Header),
+		"cookies":     r.CookiesDict(),
+		"history":     r.History(),
+		"elapsed":     startime.Duration(r.Elapsed),
+		"from_cache":  starlark.Bool(r.FromCache),
 		"encoding":    starlark.String(strings.Join(r.TransferEncoding, ",")),
 
-		"body": starlark.NewBuiltin("body", r.Text),
-		"json": starlark.NewBuiltin("json", r.JSON),
+		"body":             starlark.NewBuiltin("body", r.Text),
+		"content":          starlark.NewBuiltin("content", r.Content),
+		"json":             starlark.NewBuiltin("json", r.JSON),
+		"raise_for_status": starlark.NewBuiltin("raise_for_status", r.RaiseForStatus),
+		"iter_lines":       starlark.NewBuiltin("iter_lines", r.IterLines),
+		"iter_chunks":      starlark.NewBuiltin("iter_chunks", r.IterChunks),
 	})
 }
 
@@ -349,24 +611,88 @@ func (r *Response) HeadersDict() *starlark.Dict {
 	return d
 }
 
-// Text returns the raw data as a string
-func (r *Response) Text(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+// CookiesDict returns cookies set by the response as a dict of name to value
+func (r *Response) CookiesDict() *starlark.Dict {
+	d := new(starlark.Dict)
+	for _, c := range r.Cookies() {
+		if err := d.SetKey(starlark.String(c.Name), starlark.String(c.Value)); err != nil {
+			panic(err)
+		}
+	}
+	return d
+}
+
+// Reason returns the status text of the response, eg: "Not Found"
+func (r *Response) Reason() string {
+	if reason := strings.TrimPrefix(r.Status, strconv.Itoa(r.StatusCode)+" "); reason != "" && reason != r.Status {
+		return reason
+	}
+	return http.StatusText(r.StatusCode)
+}
+
+// OK reports whether the response status code is less than 400
+func (r *Response) OK() bool {
+	return r.StatusCode < 400
+}
+
+// History returns the responses of any redirects followed to arrive at this
+// response, oldest first
+func (r *Response) History() *starlark.List {
+	var history []starlark.Value
+	for req := r.Request; req != nil && req.Response != nil; req = req.Response.Request {
+		prev := &Response{Response: *req.Response}
+		history = append([]starlark.Value{prev.Struct()}, history...)
+	}
+	return starlark.NewList(history)
+}
+
+// readBody reads the entire response body, resetting the body reader to
+// allow multiple calls
+func (r *Response) readBody() ([]byte, error) {
 	data, err := ioutil.ReadAll(r.Body)
 	if err != nil {
 		return nil, err
 	}
 	r.Body.Close()
-	// reset reader to allow multiple calls
 	r.Body = ioutil.NopCloser(bytes.NewReader(data))
+	return data, nil
+}
+
+// Text returns the body as a string, decoded to UTF-8. The character
+// encoding is read from the optional encoding argument, then the
+// Content-Type charset, falling back to sniffing the body contents
+func (r *Response) Text(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var encoding string
+	if err := starlark.UnpackArgs("body", args, kwargs, "encoding?", &encoding); err != nil {
+		return nil, err
+	}
 
-	return starlark.String(string(data)), nil
+	data, err := r.readBody()
+	if err != nil {
+		return nil, err
+	}
+
+	text, err := decodeText(data, r.Header.Get("Content-Type"), encoding)
+	if err != nil {
+		return nil, fmt.Errorf("body: %s", err)
+	}
+	return starlark.String(text), nil
+}
+
+// Content returns the raw data as bytes
+func (r *Response) Content(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	data, err := r.readBody()
+	if err != nil {
+		return nil, err
+	}
+	return starlark.Bytes(data), nil
 }
 
 // JSON attempts to parse the response body as JSON
 func (r *Response) JSON(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 	var data interface{}
 
-	body, err := ioutil.ReadAll(r.Body)
+	body, err := r.readBody()
 	if err != nil {
 		return nil, err
 	}
@@ -374,8 +700,20 @@ func (r *Response) JSON(thread *starlark.Thread, _ *starlark.Builtin, args starl
 	if err := json.Unmarshal(body, &data); err != nil {
 		return nil, err
 	}
-	r.Body.Close()
-	// reset reader to allow multiple calls
-	r.Body = ioutil.NopCloser(bytes.NewReader(body))
 	return util.Marshal(data)
 }
+
+// RaiseForStatus returns an error if the response status code indicates a
+// client or server error
+func (r *Response) RaiseForStatus(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if err := starlark.UnpackArgs("raise_for_status", args, kwargs); err != nil {
+		return nil, err
+	}
+	switch {
+	case r.StatusCode >= 500:
+		return nil, fmt.Errorf("%d Server Error: %s for url: %s", r.StatusCode, r.Reason(), r.Request.URL)
+	case r.StatusCode >= 400:
+		return nil, fmt.Errorf("%d Client Error: %s for url: %s", r.StatusCode, r.Reason(), r.Request.URL)
+	}
+	return starlark.None, nil
+}
@@ -1,13 +1,21 @@
 package http
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
 	"io/ioutil"
+	"math"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/qri-io/starlib/testdata"
+	startime "go.starlark.net/lib/time"
 	"go.starlark.net/starlark"
 	"go.starlark.net/starlarktest"
 )
@@ -62,7 +70,7 @@ func TestSetBody(t *testing.T) {
 	}
 
 	cases := []struct {
-		rawBody      starlark.String
+		rawBody      starlark.Value
 		formData     map[string]string
 		formEncoding starlark.String
 		jsonData     starlark.Value
@@ -74,6 +82,8 @@ func TestSetBody(t *testing.T) {
 		// TODO - this should check multipart form data is being set
 		{starlark.String(""), fd, starlark.String("multipart/form-data"), nil, "", ""},
 		{starlark.String(""), nil, starlark.String(""), starlark.Tuple{starlark.Bool(true), starlark.MakeInt(1), starlark.String("der")}, "[true,1,\"der\"]", ""},
+		{starlark.Bytes("\x00\x01"), nil, starlark.String(""), nil, "\x00\x01", ""},
+		{starlark.MakeInt(1), nil, starlark.String(""), nil, "", "expected body to be a string or bytes. got: 'int'"},
 	}
 
 	for i, c := range cases {
@@ -88,7 +98,7 @@ func TestSetBody(t *testing.T) {
 		}
 
 		req := httptest.NewRequest("get", "https://example.com", nil)
-		err := setBody(req, c.rawBody, formData, c.formEncoding, c.jsonData)
+		err := setBody(req, c.rawBody, formData, c.formEncoding, c.jsonData, nil)
 		if !(err == nil && c.err == "" || (err != nil && err.Error() == c.err)) {
 			t.Errorf("case %d error mismatch. expected: %s, got: %s", i, c.err, err)
 			continue
@@ -117,3 +127,592 @@ func TestSetBody(t *testing.T) {
 		}
 	}
 }
+
+func TestSetBodyFiles(t *testing.T) {
+	files := starlark.NewDict(3)
+	files.SetKey(starlark.String("plain"), starlark.String("plain contents"))
+	files.SetKey(starlark.String("csv"), starlark.Tuple{starlark.String("data.csv"), starlark.String("a,b\n1,2\n")})
+	files.SetKey(starlark.String("image"), starlark.Tuple{starlark.String("img.png"), starlark.Bytes("\x89PNG"), starlark.String("image/png")})
+	form := starlark.NewDict(1)
+	form.SetKey(starlark.String("name"), starlark.String("upload"))
+
+	req := httptest.NewRequest("POST", "https://example.com", nil)
+	if err := setBody(req, nil, form, starlark.String(""), nil, files); err != nil {
+		t.Fatal(err)
+	}
+	if req.ContentLength <= 0 {
+		t.Errorf("expected content length to be set. got: %d", req.ContentLength)
+	}
+	if err := req.ParseMultipartForm(1 << 20); err != nil {
+		t.Fatal(err)
+	}
+	if v := req.FormValue("name"); v != "upload" {
+		t.Errorf("expected form field name=upload, got: %q", v)
+	}
+
+	expect := []struct {
+		field, filename, contentType, contents string
+	}{
+		{"plain", "plain", "application/octet-stream", "plain contents"},
+		{"csv", "data.csv", "application/octet-stream", "a,b\n1,2\n"},
+		{"image", "img.png", "image/png", "\x89PNG"},
+	}
+	for _, e := range expect {
+		fhs := req.MultipartForm.File[e.field]
+		if len(fhs) != 1 {
+			t.Errorf("expected one file for field %q, got: %d", e.field, len(fhs))
+			continue
+		}
+		fh := fhs[0]
+		if fh.Filename != e.filename {
+			t.Errorf("field %q filename mismatch. expected: %q, got: %q", e.field, e.filename, fh.Filename)
+		}
+		if ct := fh.Header.Get("Content-Type"); ct != e.contentType {
+			t.Errorf("field %q content type mismatch. expected: %q, got: %q", e.field, e.contentType, ct)
+		}
+		f, err := fh.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := ioutil.ReadAll(f)
+		f.Close()
+		if string(data) != e.contents {
+			t.Errorf("field %q contents mismatch. expected: %q, got: %q", e.field, e.contents, string(data))
+		}
+	}
+
+	bad := []struct {
+		files        starlark.Value
+		formEncoding string
+		err          string
+	}{
+		{starlark.MakeInt(1), "", "expected files value for key 'f' to be a string, bytes or tuple. got: 'int'"},
+		{starlark.Tuple{starlark.String("a")}, "", "expected files value for key 'f' to be a (filename, contents) or (filename, contents, content_type) tuple"},
+		{starlark.Tuple{starlark.String("a"), starlark.None}, "", "expected file contents for key 'f' to be a string or bytes. got: 'NoneType'"},
+		{starlark.String("data"), formEncodingURL, "files require multipart/form-data form encoding. got: \"application/x-www-form-urlencoded\""},
+	}
+	for i, c := range bad {
+		files := starlark.NewDict(1)
+		files.SetKey(starlark.String("f"), c.files)
+		req := httptest.NewRequest("POST", "https://example.com", nil)
+		err := setBody(req, nil, nil, starlark.String(c.formEncoding), nil, files)
+		if err == nil || err.Error() != c.err {
+			t.Errorf("case %d error mismatch. expected: %s, got: %v", i, c.err, err)
+		}
+	}
+}
+
+func TestSession(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: r.FormValue("user"), Path: "/"})
+		case "/private":
+			c, err := r.Cookie("session")
+			if err != nil {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			initial, _ := r.Cookie("initial")
+			fmt.Fprintf(w, `{"user":%q,"default":%q,"initial":%q}`, c.Value, r.Header.Get("X-Default"), initial.Value)
+		}
+	}))
+	defer ts.Close()
+	starlark.Universe["test_server_url"] = starlark.String(ts.URL)
+
+	// the jar scopes cookies by host name, so a server addressed as localhost
+	// is a different site from one addressed as 127.0.0.1
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		names := []string{}
+		for _, c := range r.Cookies() {
+			names = append(names, c.Name)
+		}
+		json.NewEncoder(w).Encode(names)
+	}))
+	defer other.Close()
+	starlark.Universe["third_party_url"] = starlark.String(strings.Replace(other.URL, "127.0.0.1", "localhost", 1))
+
+	thread := &starlark.Thread{Load: testdata.NewLoader(LoadModule, ModuleName)}
+	starlarktest.SetReporter(thread, t)
+
+	if _, err := starlark.ExecFile(thread, "testdata/session.star", nil, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+// retryServer fails the first two requests to each path under /flaky/ with
+// a 503, and the first request to each path under /teapot/ with a 418
+func retryServer() *httptest.Server {
+	var (
+		lk       sync.Mutex
+		attempts = map[string]int{}
+	)
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lk.Lock()
+		attempts[r.URL.Path]++
+		n := attempts[r.URL.Path]
+		lk.Unlock()
+
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/flaky/") && n <= 2:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case strings.HasPrefix(r.URL.Path, "/teapot/") && n <= 1:
+			w.WriteHeader(http.StatusTeapot)
+		case r.URL.Path == "/slow":
+			w.Header().Set("Retry-After", "3600")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case r.Method == http.MethodPost:
+			data, _ := ioutil.ReadAll(r.Body)
+			w.Write(data)
+		default:
+			fmt.Fprintf(w, "attempt %d", n)
+		}
+	}))
+}
+
+func TestRetry(t *testing.T) {
+	ts := retryServer()
+	defer ts.Close()
+	starlark.Universe["test_server_url"] = starlark.String(ts.URL)
+
+	thread := &starlark.Thread{Load: testdata.NewLoader(LoadModule, ModuleName)}
+	starlarktest.SetReporter(thread, t)
+
+	if _, err := starlark.ExecFile(thread, "testdata/retry.star", nil, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRetryMaxWait(t *testing.T) {
+	ts := retryServer()
+	defer ts.Close()
+
+	p := RetryPolicy{Retries: 5, RetryOn: []int{http.StatusTooManyRequests}, MaxWait: time.Second}
+	req := httptest.NewRequest("GET", ts.URL+"/slow", nil)
+	req.RequestURI = ""
+
+	start := time.Now()
+	res, err := p.do(req, nil, http.DefaultClient.Do)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("expected status %d, got: %d", http.StatusTooManyRequests, res.StatusCode)
+	}
+	if elapsed := time.Since(start); elapsed > p.MaxWait {
+		t.Errorf("expected retries to be capped by MaxWait. took: %s", elapsed)
+	}
+}
+
+func TestRetryWait(t *testing.T) {
+	cases := []struct {
+		p       RetryPolicy
+		attempt int
+		d       time.Duration
+	}{
+		{RetryPolicy{Backoff: time.Second}, 0, time.Second},
+		{RetryPolicy{Backoff: time.Second}, 3, 8 * time.Second},
+		{RetryPolicy{Backoff: time.Second, MaxWait: 5 * time.Second}, 3, 5 * time.Second},
+		{RetryPolicy{Backoff: time.Second, MaxWait: 30 * time.Second}, 1000, 30 * time.Second},
+		{RetryPolicy{Backoff: time.Second}, 1000, time.Duration(math.MaxInt64)},
+		{RetryPolicy{}, 1000, 0},
+	}
+
+	for i, c := range cases {
+		if d := c.p.wait(c.attempt, nil); d != c.d {
+			t.Errorf("case %d. expected: %s, got: %s", i, c.d, d)
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	cases := []struct {
+		in string
+		d  time.Duration
+		ok bool
+	}{
+		{"", 0, false},
+		{"120", 2 * time.Minute, true},
+		{"-1", 0, false},
+		{"soon", 0, false},
+		{"Mon, 01 Jun 2000 00:00:00 GMT", 0, true},
+	}
+
+	for i, c := range cases {
+		d, ok := parseRetryAfter(c.in)
+		if d != c.d || ok != c.ok {
+			t.Errorf("case %d. expected: (%s, %t), got: (%s, %t)", i, c.d, c.ok, d, ok)
+		}
+	}
+}
+
+// hangServer responds after the duration in the "wait" query param, or when
+// the client disconnects. /slow-body sends headers before waiting
+func hangServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		wait, _ := time.ParseDuration(r.FormValue("wait") + "s")
+		if r.URL.Path == "/slow-body" {
+			w.WriteHeader(http.StatusOK)
+			w.(http.Flusher).Flush()
+		}
+		select {
+		case <-time.After(wait):
+		case <-r.Context().Done():
+		}
+		w.Write([]byte("done"))
+	}))
+}
+
+func TestTimeout(t *testing.T) {
+	ts := hangServer()
+	defer ts.Close()
+	starlark.Universe["test_server_url"] = starlark.String(ts.URL)
+
+	thread := &starlark.Thread{Load: testdata.NewLoader(LoadModule, ModuleName)}
+	starlarktest.SetReporter(thread, t)
+
+	if _, err := starlark.ExecFile(thread, "testdata/timeout.star", nil, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCancelThread(t *testing.T) {
+	ts := hangServer()
+	defer ts.Close()
+
+	thread := &starlark.Thread{Load: testdata.NewLoader(LoadModule, ModuleName)}
+	SetContext(thread, context.Background())
+
+	errs := make(chan error)
+	go func() {
+		script := fmt.Sprintf("load('http.star', 'http')\nhttp.get(%q)", ts.URL+"/hang?wait=10")
+		_, err := starlark.ExecFile(thread, "cancel.star", script, nil)
+		errs <- err
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	CancelThread(thread, "test cancelled")
+
+	select {
+	case err := <-errs:
+		if err == nil || !strings.Contains(err.Error(), "context canceled") {
+			t.Errorf("expected context canceled error, got: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("in-flight request wasn't aborted by CancelThread")
+	}
+}
+
+func TestThreadCancel(t *testing.T) {
+	ts := hangServer()
+	defer ts.Close()
+
+	cases := []string{
+		// cancelled while waiting for the response
+		"http.get(%q)",
+		// cancelled while reading the response body
+		"http.get(%q).body()",
+	}
+	paths := []string{"/hang?wait=10", "/slow-body?wait=10"}
+
+	for i, c := range cases {
+		thread := &starlark.Thread{Load: testdata.NewLoader(LoadModule, ModuleName)}
+
+		errs := make(chan error)
+		go func() {
+			script := "load('http.star', 'http')\n" + fmt.Sprintf(c, ts.URL+paths[i])
+			_, err := starlark.ExecFile(thread, "cancel.star", script, nil)
+			errs <- err
+		}()
+
+		time.Sleep(50 * time.Millisecond)
+		thread.Cancel("test cancelled")
+
+		select {
+		case err := <-errs:
+			if err == nil || !strings.Contains(err.Error(), "context canceled") {
+				t.Errorf("case %d. expected context canceled error, got: %v", i, err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("case %d. in-flight request wasn't aborted by thread.Cancel", i)
+		}
+	}
+}
+
+func TestResponseBody(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/lines":
+			w.Write([]byte("a\nb\r\n\nc"))
+		case "/small":
+			w.Write([]byte(strings.Repeat("a", 16)))
+		case "/big":
+			w.Write([]byte(strings.Repeat("a", 17)))
+		}
+	}))
+	defer ts.Close()
+	starlark.Universe["test_server_url"] = starlark.String(ts.URL)
+
+	prev := MaxBodySize
+	MaxBodySize = 16
+	defer func() { MaxBodySize = prev }()
+
+	thread := &starlark.Thread{Load: testdata.NewLoader(LoadModule, ModuleName)}
+	starlarktest.SetReporter(thread, t)
+	if _, err := starlark.ExecFile(thread, "testdata/body.star", nil, nil); err != nil {
+		t.Error(err)
+	}
+
+}
+
+func TestResponse(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/redirect/2":
+			http.Redirect(w, r, "/redirect/1", http.StatusFound)
+		case r.URL.Path == "/redirect/1":
+			http.Redirect(w, r, "/final", http.StatusFound)
+		case r.URL.Path == "/final":
+			w.Header().Add("X-Multi", "a")
+			w.Header().Add("X-Multi", "b")
+			http.SetCookie(w, &http.Cookie{Name: "flavor", Value: "oatmeal"})
+			w.Write([]byte("\x00\xffbinary"))
+		case r.URL.Path == "/error":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+	starlark.Universe["test_server_url"] = starlark.String(ts.URL)
+
+	thread := &starlark.Thread{Load: func(thread *starlark.Thread, module string) (starlark.StringDict, error) {
+		if module == "time.star" {
+			return starlark.StringDict{"time": startime.Module}, nil
+		}
+		return testdata.NewLoader(LoadModule, ModuleName)(thread, module)
+	}}
+	starlarktest.SetReporter(thread, t)
+
+	if _, err := starlark.ExecFile(thread, "testdata/response.star", nil, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCharset(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/latin1":
+			w.Header().Set("Content-Type", "text/plain; charset=ISO-8859-1")
+			w.Write([]byte("caf\xe9"))
+		case "/shift_jis":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte(`<html><head><meta charset="shift_jis"></head><body>` + "\x93\xfa\x96\x7b" + `</body></html>`))
+		case "/utf8":
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte("café"))
+		case "/mislabeled":
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			w.Write([]byte("caf\xe9"))
+		}
+	}))
+	defer ts.Close()
+	starlark.Universe["test_server_url"] = starlark.String(ts.URL)
+
+	thread := &starlark.Thread{Load: testdata.NewLoader(LoadModule, ModuleName)}
+	starlarktest.SetReporter(thread, t)
+
+	if _, err := starlark.ExecFile(thread, "testdata/charset.star", nil, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAuth(t *testing.T) {
+	var (
+		lk     sync.Mutex
+		tokens int
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			id, secret, _ := r.BasicAuth()
+			if id != "client" || secret != "secret" || r.FormValue("grant_type") != "client_credentials" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if scope := r.PostFormValue("scope"); scope != "" && scope != "read write" {
+				t.Errorf("unexpected scope: %q", scope)
+			}
+			lk.Lock()
+			tokens++
+			n := tokens
+			lk.Unlock()
+			fmt.Fprintf(w, `{"access_token":"token-%d","token_type":"bearer","expires_in":%s}`, n, r.URL.Query().Get("expires_in"))
+		case "/api":
+			w.Write([]byte(r.Header.Get("Authorization")))
+		}
+	}))
+	defer ts.Close()
+	starlark.Universe["test_server_url"] = starlark.String(ts.URL)
+
+	thread := &starlark.Thread{Load: testdata.NewLoader(LoadModule, ModuleName)}
+	starlarktest.SetReporter(thread, t)
+
+	if _, err := starlark.ExecFile(thread, "testdata/auth.star", nil, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestGetAll(t *testing.T) {
+	var (
+		lk             sync.Mutex
+		inflight, peak int
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/peak" {
+			lk.Lock()
+			fmt.Fprintf(w, "%d", peak)
+			peak = 0
+			lk.Unlock()
+			return
+		}
+
+		lk.Lock()
+		inflight++
+		if inflight > peak {
+			peak = inflight
+		}
+		lk.Unlock()
+
+		time.Sleep(20 * time.Millisecond)
+		fmt.Fprintf(w, "%s %s", r.URL.Path, r.Header.Get("X-Batch"))
+
+		lk.Lock()
+		inflight--
+		lk.Unlock()
+	}))
+	defer ts.Close()
+	starlark.Universe["test_server_url"] = starlark.String(ts.URL)
+
+	thread := &starlark.Thread{Load: testdata.NewLoader(LoadModule, ModuleName)}
+	starlarktest.SetReporter(thread, t)
+
+	if _, err := starlark.ExecFile(thread, "testdata/batch.star", nil, nil); err != nil {
+		t.Error(err)
+	}
+
+	// hosts cap script concurrency
+	prev := MaxConcurrency
+	MaxConcurrency = 2
+	defer func() { MaxConcurrency = prev }()
+
+	thread = &starlark.Thread{Load: testdata.NewLoader(LoadModule, ModuleName)}
+	starlarktest.SetReporter(thread, t)
+	script := `load('http.star', 'http')
+load('assert.star', 'assert')
+http.get_all([test_server_url + "/item/%d" % i for i in range(6)], concurrency=8)
+assert.eq(http.get(test_server_url + "/peak").body(), "2")
+`
+	if _, err := starlark.ExecFile(thread, "batch_cap.star", script, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestGetAllThreadState runs get_all with an observer and guards that keep
+// per-thread state. run with -race to check state is shared safely between
+// concurrent requests
+func TestGetAllThreadState(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/redirect/") {
+			// slow redirects are checked while other responses are observed
+			time.Sleep(10 * time.Millisecond)
+			http.Redirect(w, r, strings.TrimPrefix(r.URL.Path, "/redirect"), http.StatusFound)
+			return
+		}
+		fmt.Fprint(w, r.URL.Path)
+	}))
+	defer ts.Close()
+
+	prevGuard, prevObserver := Guard, Observer
+	defer func() { Guard, Observer = prevGuard, prevObserver }()
+	Guard = Guards(AllowSchemes("http"), RequestLimit{Max: 100})
+	Observer = AuditLog{}
+
+	script := `load('http.star', 'http')
+load('assert.star', 'assert')
+# the first request fails, and is observed while redirects are checked
+urls = ["http://127.0.0.1:1/"] + [url + "/redirect/item/%d" % i for i in range(1, 20)]
+results = http.get_all(urls, concurrency=8)
+assert.true(results[0].error != None)
+assert.eq([r.response.body() for r in results[1:]], ["/item/%d" % i for i in range(1, 20)])
+`
+	// races depend on timing, repeat to give the race detector more chances
+	for i := 0; i < 20; i++ {
+		thread := &starlark.Thread{Load: testdata.NewLoader(LoadModule, ModuleName)}
+		starlarktest.SetReporter(thread, t)
+		if _, err := starlark.ExecFile(thread, "batch_state.star", script, starlark.StringDict{"url": starlark.String(ts.URL)}); err != nil {
+			t.Fatal(err)
+		}
+
+		// each request and redirect is counted
+		if n := RequestCount(thread); n != 39 {
+			t.Errorf("expected 39 requests to be counted, got: %d", n)
+		}
+		// redirects are observed like other requests
+		if n := len(AuditSummaryFor(thread).Requests); n != 39 {
+			t.Errorf("expected 39 requests to be observed, got: %d", n)
+		}
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	items := []string{"a", "b", "c", "d", "e", "f", "g"}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch r.URL.Path {
+		case "/links":
+			page, _ := strconv.Atoi(q.Get("page"))
+			if page == 0 {
+				page = 1
+			}
+			if page < 3 {
+				w.Header().Add("Link", fmt.Sprintf(`</links?page=1>; rel="first", </links?page=%d>; rel="next"`, page+1))
+			}
+			fmt.Fprintf(w, `{"page": %d, "token": %q}`, page, r.Header.Get("X-Token"))
+		case "/cursor":
+			next := map[string]string{"": "c2", "c2": "c3"}[q.Get("cursor")]
+			fmt.Fprintf(w, `{"cursor": %q, "meta": {"next": %q}}`, q.Get("cursor"), next)
+		case "/next-url":
+			if q.Get("p") == "2" {
+				fmt.Fprint(w, `{"p": 2, "next": null}`)
+				return
+			}
+			fmt.Fprint(w, `{"p": 1, "next": "/next-url?p=2"}`)
+		case "/offset":
+			offset, _ := strconv.Atoi(q.Get("offset"))
+			limit, _ := strconv.Atoi(q.Get("limit"))
+			end := offset + limit
+			if end > len(items) {
+				end = len(items)
+			}
+			data, _ := json.Marshal(items[offset:end])
+			w.Write(data)
+		case "/loop":
+			w.Header().Set("Link", `</loop>; rel="next"`)
+			fmt.Fprint(w, `{}`)
+		case "/fail":
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer ts.Close()
+	starlark.Universe["test_server_url"] = starlark.String(ts.URL)
+
+	thread := &starlark.Thread{Load: testdata.NewLoader(LoadModule, ModuleName)}
+	starlarktest.SetReporter(thread, t)
+
+	if _, err := starlark.ExecFile(thread, "testdata/paginate.star", nil, nil); err != nil {
+		t.Error(err)
+	}
+
+}
@@ -0,0 +1,399 @@
+package http
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"go.starlark.net/starlark"
+)
+
+// pagination strategies
+const (
+	nextLinkHeader = "link_header"
+	nextJSONPath   = "json_path:"
+	nextOffset     = "offset"
+)
+
+// paginate generates a starlark builtin that lazily requests pages of a
+// paginated resource: paginate(url, next="link_header", max_pages=0,
+// json=False, **kwargs). Keyword arguments paginate doesn't use are passed to
+// each GET request. A non-nil session performs requests with the session's
+// state
+func (m *Module) paginate(s *Session) func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	return func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var (
+			urlv         starlark.Value
+			next         starlark.Value = starlark.String(nextLinkHeader)
+			maxPages                    = 0
+			asJSON                      = false
+			pageSize                    = 0
+			offsetParam                 = "offset"
+			limitParam                  = "limit"
+			cursorParam                 = "cursor"
+			params                      = &starlark.Dict{}
+			reqKwargs    []starlark.Tuple
+			paginateArgs []starlark.Tuple
+		)
+
+		// separate paginate arguments from request arguments
+		for _, kw := range kwargs {
+			switch name, _ := starlark.AsString(kw[0]); name {
+			case "url", "next", "max_pages", "json", "page_size", "offset_param", "limit_param", "cursor_param", "params":
+				paginateArgs = append(paginateArgs, kw)
+			default:
+				reqKwargs = append(reqKwargs, kw)
+			}
+		}
+		if err := starlark.UnpackArgs("paginate", args, paginateArgs,
+			"url", &urlv,
+			"next?", &next,
+			"max_pages?", &maxPages,
+			"json?", &asJSON,
+			"page_size?", &pageSize,
+			"offset_param?", &offsetParam,
+			"limit_param?", &limitParam,
+			"cursor_param?", &cursorParam,
+			"params?", &params,
+		); err != nil {
+			return nil, err
+		}
+
+		rawurl, ok := starlark.AsString(urlv)
+		if !ok {
+			return nil, fmt.Errorf("paginate: expected url to be a string. got: '%s'", urlv.Type())
+		}
+		if s != nil {
+			var err error
+			if rawurl, err = s.resolveURL(rawurl); err != nil {
+				return nil, err
+			}
+		}
+		// params apply to the first page. subsequent page urls are derived from
+		// the first url, or provided by the server
+		if err := setQueryParams(&rawurl, params); err != nil {
+			return nil, err
+		}
+
+		p := &paginator{
+			m:           m,
+			s:           s,
+			thread:      thread,
+			nextURL:     rawurl,
+			maxPages:    maxPages,
+			asJSON:      asJSON,
+			pageSize:    pageSize,
+			offsetParam: offsetParam,
+			cursorParam: cursorParam,
+			kwargs:      reqKwargs,
+			seen:        map[string]bool{},
+		}
+
+		switch n := next.(type) {
+		case starlark.String:
+			switch strategy := string(n); {
+			case strategy == nextLinkHeader:
+				p.strategy = nextLinkHeader
+			case strings.HasPrefix(strategy, nextJSONPath):
+				p.strategy = nextJSONPath
+				p.path = strings.Split(strings.TrimPrefix(strategy, nextJSONPath), ".")
+			case strategy == nextOffset:
+				if pageSize < 1 {
+					return nil, fmt.Errorf("paginate: offset pagination requires a positive page_size")
+				}
+				p.strategy = nextOffset
+				u, err := url.Parse(rawurl)
+				if err != nil {
+					return nil, err
+				}
+				q := u.Query()
+				if p.offset, err = strconv.Atoi(q.Get(offsetParam)); err != nil {
+					p.offset = 0
+				}
+				q.Set(offsetParam, strconv.Itoa(p.offset))
+				q.Set(limitParam, strconv.Itoa(pageSize))
+				u.RawQuery = q.Encode()
+				p.nextURL = u.String()
+			default:
+				return nil, fmt.Errorf("paginate: unknown next strategy %q. expected %q, %q, %q or a function", strategy, nextLinkHeader, nextJSONPath+"<path>", nextOffset)
+			}
+		case starlark.Callable:
+			p.fn = n
+		default:
+			return nil, fmt.Errorf("paginate: expected next to be a string or function. got: '%s'", next.Type())
+		}
+
+		return p, nil
+	}
+}
+
+// paginator is a starlark iterable that requests a page each time the
+// iterator advances. A paginator can only be iterated once. Iteration stops
+// at the first error, which scripts can check with the error attribute
+type paginator struct {
+	m      *Module
+	s      *Session
+	thread *starlark.Thread
+	kwargs []starlark.Tuple
+
+	strategy    string
+	path        []string
+	fn          starlark.Callable
+	pageSize    int
+	offset      int
+	offsetParam string
+	cursorParam string
+
+	asJSON   bool
+	maxPages int
+	pages    int
+	nextURL  string
+	seen     map[string]bool
+	done     bool
+	err      error
+}
+
+var (
+	_ starlark.Value    = (*paginator)(nil)
+	_ starlark.Iterable = (*paginator)(nil)
+	_ starlark.Iterator = (*paginator)(nil)
+	_ starlark.HasAttrs = (*paginator)(nil)
+)
+
+// String implements the starlark.Value interface
+func (p *paginator) String() string { return "<paginator>" }
+
+// Type implements the starlark.Value interface
+func (p *paginator) Type() string { return "paginator" }
+
+// Freeze implements the starlark.Value interface
+func (p *paginator) Freeze() {}
+
+// Truth implements the starlark.Value interface
+func (p *paginator) Truth() starlark.Bool { return starlark.True }
+
+// Hash implements the starlark.Value interface
+func (p *paginator) Hash() (uint32, error) { return 0, fmt.Errorf("unhashable type: paginator") }
+
+// Attr implements the starlark.HasAttrs interface. error is the error that
+// stopped iteration, or None
+func (p *paginator) Attr(name string) (starlark.Value, error) {
+	if name != "error" {
+		return nil, nil
+	}
+	if p.err == nil {
+		return starlark.None, nil
+	}
+	return starlark.String(fmt.Sprintf("paginate: %s", p.err)), nil
+}
+
+// AttrNames implements the starlark.HasAttrs interface
+func (p *paginator) AttrNames() []string { return []string{"error"} }
+
+// Iterate implements the starlark.Iterable interface
+func (p *paginator) Iterate() starlark.Iterator { return p }
+
+// Done implements the starlark.Iterator interface
+func (p *paginator) Done() {}
+
+// Next implements the starlark.Iterator interface. starlark iterators can't
+// return errors, so errors stop iteration and are recorded on the paginator
+func (p *paginator) Next(v *starlark.Value) bool {
+	if p.done || (p.maxPages > 0 && p.pages >= p.maxPages) {
+		return false
+	}
+	page, err := p.fetch()
+	if err != nil {
+		p.done = true
+		p.err = err
+		return false
+	}
+	*v = page
+	return true
+}
+
+// fetch requests the next page, returning the page value and advancing
+// nextURL
+func (p *paginator) fetch() (starlark.Value, error) {
+	p.seen[p.nextURL] = true
+	req, opts, err := p.m.newRequest(p.thread, "get", starlark.Tuple{starlark.String(p.nextURL)}, p.kwargs, p.s)
+	if err != nil {
+		return nil, err
+	}
+	cli := p.m.cli
+	if p.s != nil {
+		cli = p.s.cli
+	}
+	res, err := p.m.send(p.thread, cli, req, opts)
+	if err != nil {
+		return nil, err
+	}
+	p.pages++
+
+	if !res.OK() {
+		if p.asJSON {
+			return nil, fmt.Errorf("page %d (%s) returned status %d %s", p.pages, res.Request.URL, res.StatusCode, res.Reason())
+		}
+		// yield the failed response, but don't request further pages
+		p.done = true
+		return res.Struct(), nil
+	}
+
+	var data starlark.Value
+	if p.asJSON || p.strategy == nextJSONPath || p.strategy == nextOffset {
+		if data, err = res.JSON(p.thread, nil, nil, nil); err != nil {
+			return nil, fmt.Errorf("page %d (%s): decoding json: %s", p.pages, res.Request.URL, err)
+		}
+	}
+
+	page := starlark.Value(res.Struct())
+	if p.asJSON {
+		page = data
+	}
+
+	next, err := p.next(res, page, data)
+	if err != nil {
+		return nil, err
+	}
+	if next == "" || p.seen[next] {
+		p.done = true
+	}
+	p.nextURL = next
+	return page, nil
+}
+
+// next determines the url of the page after res, returning an empty string
+// when there are no more pages
+func (p *paginator) next(res *Response, page, data starlark.Value) (string, error) {
+	current := res.Request.URL
+
+	if p.fn != nil {
+		v, err := starlark.Call(p.thread, p.fn, starlark.Tuple{page}, nil)
+		if err != nil {
+			return "", err
+		}
+		if v == starlark.None {
+			return "", nil
+		}
+		ref, ok := starlark.AsString(v)
+		if !ok {
+			return "", fmt.Errorf("expected next function to return a url string or None. got: '%s'", v.Type())
+		}
+		return resolveRef(current, ref)
+	}
+
+	switch p.strategy {
+	case nextLinkHeader:
+		for _, link := range res.Header["Link"] {
+			if ref := nextLink(link); ref != "" {
+				return resolveRef(current, ref)
+			}
+		}
+		return "", nil
+
+	case nextJSONPath:
+		v, err := jsonPath(data, p.path)
+		if err != nil || v == starlark.None {
+			return "", err
+		}
+		var cursor string
+		switch c := v.(type) {
+		case starlark.String:
+			cursor = string(c)
+		case starlark.Int:
+			cursor = c.String()
+		default:
+			return "", fmt.Errorf("expected value at json path %q to be a string or int. got: '%s'", strings.Join(p.path, "."), v.Type())
+		}
+		if cursor == "" {
+			return "", nil
+		}
+		// values that look like urls are followed, others are cursors
+		if strings.Contains(cursor, "://") || strings.HasPrefix(cursor, "/") || strings.HasPrefix(cursor, "?") {
+			return resolveRef(current, cursor)
+		}
+		u := *current
+		q := u.Query()
+		q.Set(p.cursorParam, cursor)
+		u.RawQuery = q.Encode()
+		return u.String(), nil
+
+	case nextOffset:
+		list, ok := data.(*starlark.List)
+		if !ok {
+			return "", fmt.Errorf("offset pagination requires json array pages. got: '%s'", data.Type())
+		}
+		if list.Len() < p.pageSize {
+			return "", nil
+		}
+		p.offset += p.pageSize
+		u := *current
+		q := u.Query()
+		q.Set(p.offsetParam, strconv.Itoa(p.offset))
+		u.RawQuery = q.Encode()
+		return u.String(), nil
+	}
+
+	return "", nil
+}
+
+// resolveRef resolves a possibly-relative url reference against base
+func resolveRef(base *url.URL, ref string) (string, error) {
+	u, err := url.Parse(ref)
+	if err != nil {
+		return "", err
+	}
+	return base.ResolveReference(u).String(), nil
+}
+
+// nextLink returns the url of the rel="next" link in a Link header value
+func nextLink(header string) string {
+	for _, link := range strings.Split(header, ",") {
+		parts := strings.Split(link, ";")
+		ref := strings.TrimSpace(parts[0])
+		if !strings.HasPrefix(ref, "<") || !strings.HasSuffix(ref, ">") {
+			continue
+		}
+		for _, param := range parts[1:] {
+			kv := strings.SplitN(strings.TrimSpace(param), "=", 2)
+			if len(kv) != 2 || !strings.EqualFold(kv[0], "rel") {
+				continue
+			}
+			for _, rel := range strings.Fields(strings.Trim(kv[1], `"`)) {
+				if strings.EqualFold(rel, "next") {
+					return ref[1 : len(ref)-1]
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// jsonPath looks up a dot-separated path in decoded json data. integer path
+// segments index lists. missing keys return None
+func jsonPath(data starlark.Value, path []string) (starlark.Value, error) {
+	v := data
+	for _, key := range path {
+		switch x := v.(type) {
+		case *starlark.Dict:
+			val, found, err := x.Get(starlark.String(key))
+			if err != nil {
+				return nil, err
+			}
+			if !found {
+				return starlark.None, nil
+			}
+			v = val
+		case *starlark.List:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= x.Len() {
+				return starlark.None, nil
+			}
+			v = x.Index(i)
+		default:
+			return starlark.None, nil
+		}
+	}
+	return v, nil
+}
@@ -0,0 +1,211 @@
+package http
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.starlark.net/starlark"
+)
+
+// RetryPolicy configures retrying failed requests. Requests are retried when
+// the transport returns an error or the response status code is listed in
+// RetryOn. Waits between attempts start at Backoff and double with each
+// attempt, unless the server provides a Retry-After header
+type RetryPolicy struct {
+	// Retries is the maximum number of times a request is retried
+	Retries int
+	// Backoff is the wait before the first retry
+	Backoff time.Duration
+	// RetryOn is the list of response status codes that trigger a retry
+	RetryOn []int
+	// MaxWait caps the total time a single request can spend waiting between
+	// attempts. A retry that would exceed MaxWait isn't performed, and the
+	// last response is returned instead. Zero means no limit. MaxWait can only
+	// be set by the host, scripts can't override it
+	MaxWait time.Duration
+	// MaxRetries is the largest number of retries scripts can ask for. Zero
+	// means no limit. MaxRetries can only be set by the host
+	MaxRetries int
+}
+
+// unpackRetryArgs overrides retry settings with the retries, backoff and
+// retry_on arguments of a starlark function call. nil values are ignored
+func (p *RetryPolicy) unpackRetryArgs(retries, backoff, retryOn starlark.Value) error {
+	if retries != nil && retries != starlark.None {
+		n, err := starlark.AsInt32(retries)
+		if err != nil {
+			return fmt.Errorf("retries: %s", err)
+		}
+		if n < 0 {
+			return fmt.Errorf("retries: expected a non-negative integer. got: %d", n)
+		}
+		if p.MaxRetries > 0 && n > p.MaxRetries {
+			return fmt.Errorf("retries: %d exceeds the maximum of %d", n, p.MaxRetries)
+		}
+		p.Retries = n
+	}
+
+	if backoff != nil && backoff != starlark.None {
+		secs, ok := starlark.AsFloat(backoff)
+		if !ok || secs < 0 {
+			return fmt.Errorf("backoff: expected a non-negative number of seconds. got: %s", backoff)
+		}
+		p.Backoff = time.Duration(secs * float64(time.Second))
+	}
+
+	if retryOn != nil && retryOn != starlark.None {
+		iterable, ok := retryOn.(starlark.Iterable)
+		if !ok {
+			return fmt.Errorf("retry_on: expected a list of status codes. got: '%s'", retryOn.Type())
+		}
+		iter := iterable.Iterate()
+		defer iter.Done()
+
+		p.RetryOn = nil
+		var x starlark.Value
+		for iter.Next(&x) {
+			code, err := starlark.AsInt32(x)
+			if err != nil {
+				return fmt.Errorf("retry_on: %s", err)
+			}
+			p.RetryOn = append(p.RetryOn, code)
+		}
+	}
+
+	return nil
+}
+
+// do performs a request with send, retrying according to the policy. Waits
+// between attempts end early if the request context is cancelled. A non-nil
+// check is called before each retry, and stops retrying if it returns an error
+func (p RetryPolicy) do(req *http.Request, check func(*http.Request) error, send func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	if p.Retries > 0 {
+		if err := rewindableBody(req); err != nil {
+			return nil, err
+		}
+	}
+
+	var waited time.Duration
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+
+		res, err := send(req)
+		if attempt >= p.Retries || req.Context().Err() != nil || !p.shouldRetry(res, err) {
+			return res, err
+		}
+
+		wait := p.wait(attempt, res)
+		if p.MaxWait > 0 && waited+wait > p.MaxWait {
+			return res, err
+		}
+		if res != nil {
+			// drain the body so the underlying connection can be reused
+			io.Copy(ioutil.Discard, res.Body)
+			res.Body.Close()
+		}
+
+		if err := sleep(req.Context(), wait); err != nil {
+			return nil, err
+		}
+		waited += wait
+
+		if check != nil {
+			if err := check(req); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// shouldRetry reports whether a request attempt failed in a retryable way
+func (p RetryPolicy) shouldRetry(res *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	for _, code := range p.RetryOn {
+		if res.StatusCode == code {
+			return true
+		}
+	}
+	return false
+}
+
+// wait calculates how long to wait before retrying, preferring the server's
+// Retry-After header over exponential backoff. backoff is capped at MaxWait
+func (p RetryPolicy) wait(attempt int, res *http.Response) time.Duration {
+	if res != nil {
+		if d, ok := parseRetryAfter(res.Header.Get("Retry-After")); ok {
+			return d
+		}
+	}
+
+	limit := time.Duration(math.MaxInt64)
+	if p.MaxWait > 0 {
+		limit = p.MaxWait
+	}
+	d := p.Backoff
+	for i := 0; i < attempt && d > 0 && d < limit; i++ {
+		if d > limit/2 {
+			d = limit
+			break
+		}
+		d *= 2
+	}
+	if d > limit {
+		d = limit
+	}
+	return d
+}
+
+// parseRetryAfter reads a Retry-After header value, which is either a number
+// of seconds or an HTTP date
+func parseRetryAfter(v string) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		d := time.Until(t)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
+
+// rewindableBody buffers a request body so it can be re-sent
+func rewindableBody(req *http.Request) error {
+	if req.Body == nil || req.GetBody != nil {
+		return nil
+	}
+
+	data, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return err
+	}
+	req.Body.Close()
+
+	req.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(data)), nil
+	}
+	req.Body, _ = req.GetBody()
+	return nil
+}
@@ -0,0 +1,201 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+)
+
+// Session is a stateful http client that persists cookies across requests,
+// merges default headers & auth into each request, and resolves relative
+// urls against a base url. All requests are checked by the module's
+// RequestGuard
+type Session struct {
+	m       *Module
+	cli     *http.Client
+	baseURL *url.URL
+	headers http.Header
+	auth    starlark.Value
+}
+
+// newSession creates a session: Session(base_url="", headers={}, auth=(), cookies={})
+func (m *Module) newSession(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var (
+		baseURL starlark.String
+		headers = &starlark.Dict{}
+		auth    starlark.Value
+		cookies = &starlark.Dict{}
+	)
+	if err := starlark.UnpackArgs("Session", args, kwargs, "base_url?", &baseURL, "headers?", &headers, "auth?", &auth, "cookies?", &cookies); err != nil {
+		return nil, err
+	}
+
+	s, err := NewSession(m, string(baseURL))
+	if err != nil {
+		return nil, err
+	}
+
+	// use a throwaway request to reuse header parsing & validation
+	hreq := &http.Request{Header: http.Header{}}
+	if err := setHeaders(hreq, headers); err != nil {
+		return nil, err
+	}
+	s.headers = hreq.Header
+
+	if err := checkAuth(auth); err != nil {
+		return nil, err
+	}
+	s.auth = auth
+
+	if cookies.Len() > 0 && s.baseURL == nil {
+		return nil, fmt.Errorf("session cookies require a base_url to scope them to")
+	}
+	var initial []*http.Cookie
+	for _, key := range cookies.Keys() {
+		val, _, err := cookies.Get(key)
+		if err != nil {
+			return nil, err
+		}
+		name, ok := starlark.AsString(key)
+		if !ok {
+			return nil, fmt.Errorf("expected cookie name to be a string. got: '%s'", key.Type())
+		}
+		value, ok := starlark.AsString(val)
+		if !ok {
+			return nil, fmt.Errorf("expected cookie value for key '%s' to be a string. got: '%s'", name, val.Type())
+		}
+		initial = append(initial, &http.Cookie{Name: name, Value: value, Path: "/"})
+	}
+	// initial cookies are host-only cookies for the base url, the jar decides
+	// which requests they're sent with
+	if len(initial) > 0 {
+		s.cli.Jar.SetCookies(s.baseURL, initial)
+	}
+
+	return s.Struct(), nil
+}
+
+// NewSession creates a session that issues requests through module m. An
+// empty baseURL disables relative url resolution
+func NewSession(m *Module, baseURL string) (*Session, error) {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	cli := &http.Client{}
+	if m.cli != nil {
+		*cli = *m.cli
+	}
+	cli.Jar = jar
+
+	s := &Session{m: m, cli: cli, headers: http.Header{}}
+	if baseURL != "" {
+		if s.baseURL, err = url.Parse(baseURL); err != nil {
+			return nil, err
+		}
+		if !s.baseURL.IsAbs() {
+			return nil, fmt.Errorf("session base_url must be an absolute url. got: '%s'", baseURL)
+		}
+	}
+	return s, nil
+}
+
+// Struct returns this session's methods as a starlark Struct
+func (s *Session) Struct() *starlarkstruct.Struct {
+	base := ""
+	if s.baseURL != nil {
+		base = s.baseURL.String()
+	}
+
+	headers := new(starlark.Dict)
+	for key, vals := range s.headers {
+		if err := headers.SetKey(starlark.String(key), starlark.String(strings.Join(vals, ","))); err != nil {
+			panic(err)
+		}
+	}
+	headers.Freeze()
+
+	return starlarkstruct.FromStringDict(starlark.String("Session"), starlark.StringDict{
+		"base_url": starlark.String(base),
+		"headers":  headers,
+
+		"get":      starlark.NewBuiltin("get", s.reqMethod("get")),
+		"put":      starlark.NewBuiltin("put", s.reqMethod("put")),
+		"post":     starlark.NewBuiltin("post", s.reqMethod("post")),
+		"delete":   starlark.NewBuiltin("delete", s.reqMethod("delete")),
+		"patch":    starlark.NewBuiltin("patch", s.reqMethod("patch")),
+		"options":  starlark.NewBuiltin("options", s.reqMethod("options")),
+		"get_all":  starlark.NewBuiltin("get_all", s.m.getAll(s)),
+		"paginate": starlark.NewBuiltin("paginate", s.m.paginate(s)),
+
+		"cookies": starlark.NewBuiltin("cookies", s.cookiesDict),
+	})
+}
+
+// reqMethod generates starlark builtin functions for session request methods
+func (s *Session) reqMethod(method string) func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	return func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		req, opts, err := s.m.newRequest(thread, method, args, kwargs, s)
+		if err != nil {
+			return nil, err
+		}
+		return s.m.do(thread, s.cli, req, opts)
+	}
+}
+
+// cookiesDict returns cookies the session jar will send to a url, defaulting
+// to the session base url
+func (s *Session) cookiesDict(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var urlv starlark.String
+	if err := starlark.UnpackArgs("cookies", args, kwargs, "url?", &urlv); err != nil {
+		return nil, err
+	}
+
+	rawurl, err := s.resolveURL(string(urlv))
+	if err != nil {
+		return nil, err
+	}
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, err
+	}
+
+	d := new(starlark.Dict)
+	for _, c := range s.cli.Jar.Cookies(u) {
+		if err := d.SetKey(starlark.String(c.Name), starlark.String(c.Value)); err != nil {
+			return nil, err
+		}
+	}
+	return d, nil
+}
+
+// resolveURL resolves a possibly-relative url against the session base url
+func (s *Session) resolveURL(rawurl string) (string, error) {
+	if s.baseURL == nil {
+		return rawurl, nil
+	}
+	ref, err := url.Parse(rawurl)
+	if err != nil {
+		return "", err
+	}
+	return s.baseURL.ResolveReference(ref).String(), nil
+}
+
+// setDefaults adds session headers to a request without overriding values
+// the request already sets. Cookies are added by the session cookie jar
+func (s *Session) setDefaults(req *http.Request) {
+	for key, vals := range s.headers {
+		if _, ok := req.Header[key]; ok {
+			continue
+		}
+		for _, v := range vals {
+			req.Header.Add(key, v)
+		}
+	}
+}